@@ -0,0 +1,280 @@
+package gorequest
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// A LoadReport sums up the requests sent by Bench or RunLoad.
+type LoadReport struct {
+	// Requests is the number of requests sent, failed or not.
+	Requests int
+	// Duration is the time from the first request sent to the last response received.
+	Duration time.Duration
+	// Throughput is the number of requests completed per second.
+	Throughput float64
+	Latency    LatencyStats
+	// Statuses counts the responses by status code.
+	Statuses map[int]int
+	// Errors counts the failed requests by class: "timeout", "connection refused",
+	// "connection reset", "dns", "tls", "canceled" or "other".
+	Errors map[string]int
+	// Dropped is the number of requests RunLoad didn't send, as too many were in flight.
+	Dropped int
+}
+
+// LatencyStats are the latencies of the requests, failed or not.
+type LatencyStats struct {
+	Min, Mean, P50, P90, P95, P99, Max time.Duration
+}
+
+func (r LoadReport) String() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "Requests:   %d in %v, %.1f req/s\n", r.Requests, r.Duration.Round(time.Millisecond), r.Throughput)
+	l := r.Latency
+	fmt.Fprintf(&out, "Latency:    min %v, mean %v, p50 %v, p90 %v, p95 %v, p99 %v, max %v\n",
+		roundLatency(l.Min), roundLatency(l.Mean), roundLatency(l.P50), roundLatency(l.P90),
+		roundLatency(l.P95), roundLatency(l.P99), roundLatency(l.Max))
+	statuses := make([]int, 0, len(r.Statuses))
+	for status := range r.Statuses {
+		statuses = append(statuses, status)
+	}
+	sort.Ints(statuses)
+	counts := make([]string, len(statuses))
+	for i, status := range statuses {
+		counts[i] = fmt.Sprintf("%d: %d", status, r.Statuses[status])
+	}
+	fmt.Fprintf(&out, "Statuses:   %s\n", strings.Join(counts, ", "))
+	if len(r.Errors) != 0 {
+		counts = counts[:0]
+		for _, class := range sortedCountKeys(r.Errors) {
+			counts = append(counts, fmt.Sprintf("%s: %d", class, r.Errors[class]))
+		}
+		fmt.Fprintf(&out, "Errors:     %s\n", strings.Join(counts, ", "))
+	}
+	if r.Dropped != 0 {
+		fmt.Fprintf(&out, "Dropped:    %d, too many requests in flight\n", r.Dropped)
+	}
+	return out.String()
+}
+
+func roundLatency(d time.Duration) time.Duration {
+	if d >= time.Second {
+		return d.Round(time.Millisecond)
+	}
+	return d.Round(10 * time.Microsecond)
+}
+
+func sortedCountKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Bench sends n clones of the prepared request, concurrency at a time, and reports how
+// they went. It is meant for quick capacity checks, e.g. against a local stand-in:
+//
+//	report := gorequest.New().
+//	  Post("http://localhost:8080/users").
+//	  Send(`{"name":"gopher"}`).
+//	  Bench(1000, 10)
+//	fmt.Print(report)
+//
+// As every request is a Clone, the request must be prepared before calling Bench.
+func (s *SuperAgent) Bench(n, concurrency int) LoadReport {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	collector := newLoadCollector(n)
+	jobs := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				collector.send(s.Clone())
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+	wg.Wait()
+	return collector.report()
+}
+
+// RunLoad sends clones of the prepared request at rate requests per second for duration,
+// whatever the time the responses take, and reports how they went once every response is
+// received. See Bench.
+//
+// At most maxInFlight requests are sent at a time, at least 1, so that a slow server
+// doesn't pile up goroutines and connections. The requests which would exceed it are not
+// sent, and counted in LoadReport.Dropped.
+func (s *SuperAgent) RunLoad(rate float64, duration time.Duration, maxInFlight int) LoadReport {
+	collector := newLoadCollector(int(rate * duration.Seconds()))
+	if rate <= 0 {
+		return collector.report()
+	}
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+	inFlight := make(chan struct{}, maxInFlight)
+	dropped := 0
+	interval := time.Duration(float64(time.Second) / rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.After(duration)
+	var wg sync.WaitGroup
+	send := func() {
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			dropped++
+			return
+		}
+		wg.Add(1)
+		go func(request *SuperAgent) {
+			defer wg.Done()
+			collector.send(request)
+			<-inFlight
+		}(s.Clone())
+	}
+	send()
+loop:
+	for {
+		select {
+		case <-ticker.C:
+			send()
+		case <-deadline:
+			break loop
+		}
+	}
+	wg.Wait()
+	report := collector.report()
+	report.Dropped = dropped
+	return report
+}
+
+// A loadCollector gathers the outcome of the requests of a load test.
+type loadCollector struct {
+	mu        sync.Mutex
+	start     time.Time
+	end       time.Time
+	latencies []time.Duration
+	statuses  map[int]int
+	errors    map[string]int
+}
+
+func newLoadCollector(capacity int) *loadCollector {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &loadCollector{
+		latencies: make([]time.Duration, 0, capacity),
+		statuses:  map[int]int{},
+		errors:    map[string]int{},
+	}
+}
+
+// send sends request and records its outcome.
+func (c *loadCollector) send(request *SuperAgent) {
+	start := time.Now()
+	resp, _, errs := request.EndBytes()
+	end := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.start.IsZero() || start.Before(c.start) {
+		c.start = start
+	}
+	if end.After(c.end) {
+		c.end = end
+	}
+	c.latencies = append(c.latencies, end.Sub(start))
+	if errs != nil {
+		c.errors[errorClass(errs[0])]++
+	} else {
+		c.statuses[resp.StatusCode]++
+	}
+}
+
+func (c *loadCollector) report() LoadReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	r := LoadReport{Requests: len(c.latencies), Statuses: c.statuses, Errors: c.errors}
+	if len(c.latencies) == 0 {
+		return r
+	}
+	r.Duration = c.end.Sub(c.start)
+	if r.Duration > 0 {
+		r.Throughput = float64(r.Requests) / r.Duration.Seconds()
+	}
+	sorted := append([]time.Duration(nil), c.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	percentile := func(p float64) time.Duration {
+		// nearest rank
+		rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		return sorted[rank]
+	}
+	r.Latency = LatencyStats{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(0.50),
+		P90:  percentile(0.90),
+		P95:  percentile(0.95),
+		P99:  percentile(0.99),
+		Max:  sorted[len(sorted)-1],
+	}
+	return r
+}
+
+// errorClass returns the class of a request error, see LoadReport.Errors.
+func errorClass(err error) string {
+	var (
+		netErr     net.Error
+		dnsErr     *net.DNSError
+		recordErr  tls.RecordHeaderError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused"
+	case errors.Is(err, syscall.ECONNRESET):
+		return "connection reset"
+	case errors.As(err, &recordErr), errors.As(err, &unknownCA), errors.As(err, &hostErr), errors.As(err, &invalidErr),
+		strings.Contains(err.Error(), "tls: "):
+		return "tls"
+	}
+	return "other"
+}
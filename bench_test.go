@@ -0,0 +1,101 @@
+package gorequest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestBench(t *testing.T) {
+	var calls, inFlight, maxInFlight int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		if atomic.AddInt32(&calls, 1)%10 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	report := New().Get(ts.URL).Bench(50, 5)
+	if report.Requests != 50 || report.Statuses[http.StatusOK] != 45 || report.Statuses[http.StatusServiceUnavailable] != 5 || len(report.Errors) != 0 {
+		t.Error(fmt.Sprintf("Expected 45 OK and 5 unavailable | but got %+v", report))
+	}
+	if maxInFlight > 5 {
+		t.Error(fmt.Sprintf("Expected at most 5 concurrent requests | but got %d", maxInFlight))
+	}
+	l := report.Latency
+	if l.Min < 2*time.Millisecond || l.Min > l.P50 || l.P50 > l.P90 || l.P90 > l.P99 || l.P99 > l.Max || report.Throughput <= 0 {
+		t.Error(fmt.Sprintf("Expected ordered latencies | but got %+v", report))
+	}
+	if s := report.String(); !strings.Contains(s, "Requests:   50 in ") || !strings.Contains(s, "Statuses:   200: 45, 503: 5\n") {
+		t.Error(fmt.Sprintf("Expected a readable report | but got %s", s))
+	}
+
+	report = New().Get("http://127.0.0.1:1").Bench(3, 2)
+	if report.Errors["connection refused"] != 3 || !strings.Contains(report.String(), "Errors:     connection refused: 3") {
+		t.Error(fmt.Sprintf("Expected 3 connection refused errors | but got %+v", report))
+	}
+}
+
+func TestRunLoad(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	report := New().Get(ts.URL).RunLoad(200, 100*time.Millisecond, 10)
+	if report.Requests < 5 || report.Requests > 30 || report.Statuses[http.StatusOK] != report.Requests || report.Dropped != 0 {
+		t.Error(fmt.Sprintf("Expected about 20 requests | but got %+v", report))
+	}
+
+	// requests beyond maxInFlight are dropped
+	var inFlight, maxInFlight int32
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}))
+	defer slow.Close()
+	report = New().Get(slow.URL).RunLoad(500, 100*time.Millisecond, 3)
+	if maxInFlight > 3 || report.Dropped == 0 || report.Requests+report.Dropped < 20 ||
+		!strings.Contains(report.String(), "Dropped:    ") {
+		t.Error(fmt.Sprintf("Expected at most 3 requests in flight and dropped ones | but got %d, %+v", maxInFlight, report))
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	for err, class := range map[error]string{
+		context.Canceled: "canceled",
+		errors.Wrap(context.DeadlineExceeded, "Get"):                "timeout",
+		&net.DNSError{Err: "no such host", Name: "example.invalid"}: "dns",
+		&net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}:         "connection refused",
+		&net.OpError{Op: "read", Err: syscall.ECONNRESET}:           "connection reset",
+		errors.New("remote error: tls: handshake failure"):          "tls",
+		errors.New("boom"): "other",
+	} {
+		if actual := errorClass(err); actual != class {
+			t.Error(fmt.Sprintf("Expected %s for %v | but got %s", class, err, actual))
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// ErrCassetteMiss is returned in replay mode for requests matching no recorded interaction.
+var ErrCassetteMiss = errors.New("gorequest: no cassette interaction matches the request")
+
+// Modes of a Cassette.
+const (
+	// CassetteReplay replays the recorded interactions and never sends requests.
+	CassetteReplay = iota
+	// CassetteRecord sends the requests and records them, replacing the cassette.
+	CassetteRecord
+	// CassetteReplayOrRecord replays the cassette if it exists, and records it otherwise.
+	CassetteReplayOrRecord
+)
+
+// A Cassette records exchanges to a file once, and replays them afterwards, see UseCassette.
+// Interactions are replayed at most once, in the order they were recorded. Recorded
+// interactions are written to the file by Close.
+type Cassette struct {
+	// Match selects the fields compared between requests and recorded interactions.
+	// It defaults to the method and url.
+	Match CassetteMatch
+	// ScrubHeaders are the headers whose values are replaced by Redacted when recording,
+	// DefaultRedactionPolicy.Headers by default. Urls and bodies are redacted with the
+	// redaction policy of the SuperAgent, see SetRedaction.
+	ScrubHeaders []string
+
+	filename     string
+	recording    bool
+	mu           sync.Mutex
+	interactions []CassetteInteraction
+	played       []bool
+}
+
+// CassetteMatch selects the fields compared in replay mode.
+type CassetteMatch struct {
+	Method bool
+	URL    bool
+	Body   bool
+	// Headers are the names of the headers compared. Scrubbed headers can't be compared.
+	// Bodies are compared once redacted.
+	Headers []string
+}
+
+// A CassetteInteraction is a recorded exchange.
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+type CassetteRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	CassetteBody
+}
+
+type CassetteResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	CassetteBody
+}
+
+// A CassetteBody holds a body as text, or as base64 if it isn't valid UTF-8.
+type CassetteBody struct {
+	Body       string `json:"body,omitempty"`
+	BodyBase64 bool   `json:"body_base64,omitempty"`
+}
+
+func newCassetteBody(body []byte) CassetteBody {
+	if utf8.Valid(body) {
+		return CassetteBody{Body: string(body)}
+	}
+	return CassetteBody{Body: base64.StdEncoding.EncodeToString(body), BodyBase64: true}
+}
+
+func (b CassetteBody) bytes() []byte {
+	if b.BodyBase64 {
+		data, _ := base64.StdEncoding.DecodeString(b.Body)
+		return data
+	}
+	return []byte(b.Body)
+}
+
+// NewCassette returns the cassette stored in filename, loaded unless recording:
+//
+//	cassette, err := gorequest.NewCassette("testdata/users.json", gorequest.CassetteReplayOrRecord)
+//	if err != nil {
+//	  t.Fatal(err)
+//	}
+//	defer cassette.Close()
+//	resp, body, errs := gorequest.New().UseCassette(cassette).Get("https://api.example.com/users").End()
+func NewCassette(filename string, mode int) (*Cassette, error) {
+	c := &Cassette{
+		Match:        CassetteMatch{Method: true, URL: true},
+		ScrubHeaders: DefaultRedactionPolicy.Headers,
+		filename:     filename,
+	}
+	switch mode {
+	case CassetteRecord:
+		c.recording = true
+		return c, nil
+	case CassetteReplayOrRecord:
+		if _, err := os.Stat(filename); os.IsNotExist(err) {
+			c.recording = true
+			return c, nil
+		}
+	case CassetteReplay:
+	default:
+		return nil, errors.Errorf("gorequest: unknown cassette mode %d", mode)
+	}
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var stored struct {
+		Interactions []CassetteInteraction `json:"interactions"`
+	}
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, errors.Wrapf(err, "gorequest: invalid cassette %s", filename)
+	}
+	c.interactions = stored.Interactions
+	c.played = make([]bool, len(stored.Interactions))
+	return c, nil
+}
+
+// UseCassette makes the SuperAgent record its exchanges in cassette, or replay them from it.
+// In replay mode, requests matching no interaction fail with ErrCassetteMiss.
+func (s *SuperAgent) UseCassette(cassette *Cassette) *SuperAgent {
+	s.cassette = cassette
+	return s
+}
+
+// UseCassette returns a Client using cassette, see SuperAgent.UseCassette.
+func (c *Client) UseCassette(cassette *Cassette) *Client {
+	return c.with(func(s *SuperAgent) { s.UseCassette(cassette) })
+}
+
+// Recording tells whether the cassette records exchanges, or replays them.
+func (c *Cassette) Recording() bool {
+	return c.recording
+}
+
+// Close writes the recorded interactions to the file of the cassette. It does nothing in
+// replay mode.
+func (c *Cassette) Close() error {
+	if !c.recording {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := json.MarshalIndent(struct {
+		Interactions []CassetteInteraction `json:"interactions"`
+	}{c.interactions}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.filename, append(data, '\n'), 0644)
+}
+
+// Interactions returns the interactions of the cassette.
+func (c *Cassette) Interactions() []CassetteInteraction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CassetteInteraction(nil), c.interactions...)
+}
+
+// Unplayed returns the number of interactions not replayed yet.
+func (c *Cassette) Unplayed() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := 0
+	for _, played := range c.played {
+		if !played {
+			n++
+		}
+	}
+	return n
+}
+
+// A cassetteTransport records exchanges in a cassette or replays them.
+type cassetteTransport struct {
+	base     http.RoundTripper
+	cassette *Cassette
+	// policy redacts the urls and bodies, see SetRedaction.
+	policy *RedactionPolicy
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, req, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if t.cassette.recording {
+		return t.record(req, body)
+	}
+	return t.cassette.replay(req, t.policy.redactURL(req.URL).String(), t.policy.redactBody(req.Header.Get("Content-Type"), body))
+}
+
+// readRequestBody returns the body of req, and a request equal to req whose body is unread.
+func readRequestBody(req *http.Request) ([]byte, *http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, req, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer body.Close()
+		data, err := ioutil.ReadAll(body)
+		return data, req, err
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, req, nil
+}
+
+func (t *cassetteTransport) record(req *http.Request, reqBody []byte) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	c := t.cassette
+	scrub := &RedactionPolicy{Headers: c.ScrubHeaders}
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method:       req.Method,
+			URL:          t.policy.redactURL(req.URL).String(),
+			Header:       scrub.redactHeader(req.Header),
+			CassetteBody: newCassetteBody(t.policy.redactBody(req.Header.Get("Content-Type"), reqBody)),
+		},
+		Response: CassetteResponse{
+			StatusCode:   resp.StatusCode,
+			Header:       scrub.redactHeader(resp.Header),
+			CassetteBody: newCassetteBody(t.policy.redactBody(resp.Header.Get("Content-Type"), body)),
+		},
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.interactions = append(c.interactions, interaction)
+	c.played = append(c.played, true)
+	return resp, nil
+}
+
+// replay returns the response of the first unplayed interaction matching req, whose url
+// and body are the redacted ones.
+func (c *Cassette) replay(req *http.Request, reqURL string, body []byte) (*http.Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, interaction := range c.interactions {
+		if c.played[i] || !c.matches(interaction.Request, req, reqURL, body) {
+			continue
+		}
+		c.played[i] = true
+		recorded := interaction.Response
+		respBody := recorded.bytes()
+		header := recorded.Header
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header.Clone(),
+			Body:          ioutil.NopCloser(bytes.NewReader(respBody)),
+			ContentLength: int64(len(respBody)),
+			Request:       req,
+		}, nil
+	}
+	return nil, errors.Wrapf(ErrCassetteMiss, "%s %s in %s", req.Method, reqURL, c.filename)
+}
+
+// matches tells whether req, of redacted url and body, matches a recorded request,
+// according to Match.
+func (c *Cassette) matches(recorded CassetteRequest, req *http.Request, reqURL string, body []byte) bool {
+	if c.Match.Method && recorded.Method != req.Method {
+		return false
+	}
+	if c.Match.URL && recorded.URL != reqURL {
+		return false
+	}
+	if c.Match.Body && !bytes.Equal(recorded.bytes(), body) {
+		return false
+	}
+	for _, name := range c.Match.Headers {
+		if fmt.Sprint(recorded.Header.Values(name)) != fmt.Sprint(req.Header.Values(name)) {
+			return false
+		}
+	}
+	return true
+}
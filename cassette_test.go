@@ -0,0 +1,89 @@
+package gorequest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+func TestCassette(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if r.URL.Path == "/login" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token":"secret-token"}`)
+			return
+		}
+		w.Header().Set("X-Method", r.Method)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "%s %s %s", r.Method, r.URL.Path, body)
+	}))
+	filename := filepath.Join(t.TempDir(), "cassette.json")
+
+	cassette, err := NewCassette(filename, CassetteReplayOrRecord)
+	if err != nil || !cassette.Recording() {
+		t.Fatal(fmt.Sprintf("Expected a recording cassette | but got %v", err))
+	}
+	client := NewClient(ts.URL).UseCassette(cassette).Set("Authorization", "Bearer secret")
+	client.Post("/users").Send(`{"name":"a"}`).End()
+	client.Post("/users").Send(`{"name":"b"}`).End()
+	client.Get("/binary").Query("api_key=secret-key&page=1").End()
+	client.Post("/login").Send(`{"user":"me","password":"secret-password"}`).End()
+	ts.Close()
+
+	if _, err := ioutil.ReadFile(filename); err == nil {
+		t.Error("Expected the cassette to be written on Close only")
+	}
+	if err := cassette.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := ioutil.ReadFile(filename)
+	if strings.Contains(string(data), "secret") || !strings.Contains(string(data), Redacted) {
+		t.Error(fmt.Sprintf("Expected scrubbed headers, urls and bodies in cassette | but got %s", data))
+	}
+
+	cassette, err = NewCassette(filename, CassetteReplayOrRecord)
+	if err != nil || cassette.Recording() || len(cassette.Interactions()) != 4 {
+		t.Fatal(fmt.Sprintf("Expected a replaying cassette | but got %v", err))
+	}
+	cassette.Match.Body = true
+	client = NewClient(ts.URL).UseCassette(cassette)
+	resp, body, errs := client.Post("/users").Send(`{"name":"b"}`).End()
+	if errs != nil || resp.StatusCode != http.StatusCreated || resp.Header.Get("X-Method") != "POST" || body != `POST /users {"name":"b"}` {
+		t.Error(fmt.Sprintf("Expected recorded response | but got %v, %q, %v", resp, body, errs))
+	}
+	if cassette.Unplayed() != 3 {
+		t.Error(fmt.Sprintf("Expected 3 unplayed interactions | but got %d", cassette.Unplayed()))
+	}
+	// bodies are compared once redacted
+	_, body, errs = client.Post("/login").Send(`{"user":"me","password":"other-password"}`).End()
+	if errs != nil || body != `{"access_token":"[REDACTED]"}` {
+		t.Error(fmt.Sprintf("Expected the redacted login response | but got %q, %v", body, errs))
+	}
+
+	// urls are compared once redacted
+	if _, body, errs = client.Get("/binary").Query("api_key=other-key&page=1").End(); errs != nil || body != "GET /binary " {
+		t.Error(fmt.Sprintf("Expected the recorded binary response | but got %q, %v", body, errs))
+	}
+
+	// interactions are replayed once
+	_, _, errs = client.Post("/users").Send(`{"name":"b"}`).End()
+	if len(errs) != 1 || !errors.Is(errs[0], ErrCassetteMiss) {
+		t.Error(fmt.Sprintf("Expected ErrCassetteMiss | but got %v", errs))
+	}
+	_, _, errs = client.Delete("/users").End()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "DELETE "+ts.URL+"/users in "+filename) {
+		t.Error(fmt.Sprintf("Expected a descriptive miss | but got %v", errs))
+	}
+
+	if _, err := NewCassette(filepath.Join(t.TempDir(), "missing.json"), CassetteReplay); err == nil {
+		t.Error("Expected replaying a missing cassette to fail")
+	}
+}
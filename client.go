@@ -0,0 +1,185 @@
+package gorequest
+
+import (
+	"crypto/tls"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// A Client holds the settings shared by the requests made to one service: a base url,
+// default headers and query parameters, basic auth, timeout and retry policy, along with
+// the http.Client and Transport. Every request started from a Client gets a copy of these
+// defaults, which it can override without changing the Client:
+//
+//	client := gorequest.NewClient("https://api.example.com/v2").
+//	  Set("Accept", "application/json").
+//	  SetBasicAuth("user", "password").
+//	  Timeout(10 * time.Second)
+//
+//	resp, body, errs := client.Get("/users/{id}").PathParam("id", 42).End()
+//	resp, body, errs = client.Post("/users").Set("Accept", "text/plain").Send(user).End()
+//
+// A Client is immutable: its setters return a new Client and leave the receiver as it is.
+// This makes a Client safe for concurrent use by multiple goroutines, while each request
+// is a SuperAgent used by one goroutine only. Clients derived from each other share their
+// transport, and so their connections, unless TLSClientConfig or Proxy is changed.
+type Client struct {
+	agent *SuperAgent
+}
+
+// NewClient returns a Client which resolves relative urls against baseURL (see BaseURL).
+// baseURL may be empty.
+func NewClient(baseURL string) *Client {
+	return &Client{agent: New().BaseURL(baseURL)}
+}
+
+// Agent returns a new SuperAgent holding the Client defaults, see SuperAgent.Clone.
+func (c *Client) Agent() *SuperAgent {
+	return c.agent.Clone()
+}
+
+// Errors returns the errors found while configuring the Client, e.g. by Retry with an unknown status code.
+func (c *Client) Errors() []error {
+	return shallowCopyErrors(c.agent.Errors)
+}
+
+// with returns a new Client with the settings of c changed by modify.
+func (c *Client) with(modify func(s *SuperAgent)) *Client {
+	agent := c.agent.Clone()
+	modify(agent)
+	return &Client{agent: agent}
+}
+
+// BaseURL returns a Client with another base url, see SuperAgent.BaseURL.
+func (c *Client) BaseURL(baseURL string) *Client {
+	return c.with(func(s *SuperAgent) { s.BaseURL(baseURL) })
+}
+
+// Set returns a Client with a default header, see SuperAgent.Set.
+func (c *Client) Set(param string, value string) *Client {
+	return c.with(func(s *SuperAgent) { s.Set(param, value) })
+}
+
+// AppendHeader returns a Client with another default header value, see SuperAgent.AppendHeader.
+func (c *Client) AppendHeader(param string, value string) *Client {
+	return c.with(func(s *SuperAgent) { s.AppendHeader(param, value) })
+}
+
+// Query returns a Client with default query parameters, see SuperAgent.Query. A parameter
+// set by a request, with Query, Param or in its url, replaces the default values of its key.
+func (c *Client) Query(content interface{}) *Client {
+	return c.with(func(s *SuperAgent) { s.Query(content) })
+}
+
+// Param returns a Client with a default query parameter, see SuperAgent.Param and Query.
+func (c *Client) Param(key string, value string) *Client {
+	return c.with(func(s *SuperAgent) { s.Param(key, value) })
+}
+
+// SetBasicAuth returns a Client with default basic authentication, see SuperAgent.SetBasicAuth.
+func (c *Client) SetBasicAuth(username string, password string) *Client {
+	return c.with(func(s *SuperAgent) { s.SetBasicAuth(username, password) })
+}
+
+// Timeout returns a Client with a timeout for every request, see SuperAgent.Timeout.
+func (c *Client) Timeout(timeout time.Duration) *Client {
+	return c.with(func(s *SuperAgent) { s.Timeout(timeout) })
+}
+
+// Retry returns a Client with a default retry policy, see SuperAgent.Retry.
+func (c *Client) Retry(retryerCount int, retryerTime time.Duration, statusCode ...int) *Client {
+	return c.with(func(s *SuperAgent) { s.Retry(retryerCount, retryerTime, statusCode...) })
+}
+
+// TLSClientConfig returns a Client with its own transport using config, see SuperAgent.TLSClientConfig.
+func (c *Client) TLSClientConfig(config *tls.Config) *Client {
+	return c.with(func(s *SuperAgent) { s.TLSClientConfig(config) })
+}
+
+// Proxy returns a Client with its own transport using the proxy, see SuperAgent.Proxy.
+func (c *Client) Proxy(proxyUrl string) *Client {
+	return c.with(func(s *SuperAgent) { s.Proxy(proxyUrl) })
+}
+
+// SetDebug returns a Client with the debug mode of every request enabled or disabled, see SuperAgent.SetDebug.
+func (c *Client) SetDebug(enable bool) *Client {
+	return c.with(func(s *SuperAgent) { s.SetDebug(enable) })
+}
+
+// SetLogger returns a Client with the logger of every request, see SuperAgent.SetLogger.
+func (c *Client) SetLogger(logger Logger) *Client {
+	return c.with(func(s *SuperAgent) { s.SetLogger(logger) })
+}
+
+// CustomMethod starts a request with the Client defaults, see SuperAgent.CustomMethod.
+// Errors found while configuring the Client are returned by End. Calling Get, Post, etc.
+// on the returned request starts another one, from the Client defaults again.
+func (c *Client) CustomMethod(method, targetUrl string) *SuperAgent {
+	s := c.Agent()
+	// unlike a clone, the request is cleared by its next Get, Post, etc., back to the
+	// Client defaults, so that it doesn't resend the body of the previous request
+	s.DoNotClearSuperAgent = false
+	s.clientDefaults = c.agent
+	return s.CustomMethod(method, targetUrl)
+}
+
+func (c *Client) Get(targetUrl string) *SuperAgent {
+	return c.CustomMethod(GET, targetUrl)
+}
+
+func (c *Client) Post(targetUrl string) *SuperAgent {
+	return c.CustomMethod(POST, targetUrl)
+}
+
+func (c *Client) Head(targetUrl string) *SuperAgent {
+	return c.CustomMethod(HEAD, targetUrl)
+}
+
+func (c *Client) Put(targetUrl string) *SuperAgent {
+	return c.CustomMethod(PUT, targetUrl)
+}
+
+func (c *Client) Delete(targetUrl string) *SuperAgent {
+	return c.CustomMethod(DELETE, targetUrl)
+}
+
+func (c *Client) Patch(targetUrl string) *SuperAgent {
+	return c.CustomMethod(PATCH, targetUrl)
+}
+
+func (c *Client) Options(targetUrl string) *SuperAgent {
+	return c.CustomMethod(OPTIONS, targetUrl)
+}
+
+// BaseURL sets the url which relative urls given to Get, Post, etc. are resolved against.
+// It is kept by ClearSuperAgent and Clone. The path of the request is appended to the
+// path of the base url:
+//
+//	gorequest.New().
+//	  BaseURL("https://api.example.com/v2").
+//	  Get("/users").
+//	  End()
+//
+// This will GET https://api.example.com/v2/users. Absolute urls are sent as they are.
+func (s *SuperAgent) BaseURL(baseURL string) *SuperAgent {
+	s.baseURL = baseURL
+	return s
+}
+
+// resolveURL returns targetUrl joined to baseURL, unless targetUrl is absolute.
+func resolveURL(baseURL, targetUrl string) string {
+	if baseURL == "" {
+		return targetUrl
+	}
+	if u, err := url.Parse(targetUrl); err == nil && u.IsAbs() {
+		return targetUrl
+	}
+	if targetUrl == "" {
+		return baseURL
+	}
+	if strings.HasPrefix(targetUrl, "?") || strings.HasPrefix(targetUrl, "#") {
+		return baseURL + targetUrl
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(targetUrl, "/")
+}
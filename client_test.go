@@ -0,0 +1,167 @@
+package gorequest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestResolveURL(t *testing.T) {
+	var cases = []struct {
+		base, target, want string
+	}{
+		{"", "/users", "/users"},
+		{"https://api.example.com/v2", "/users", "https://api.example.com/v2/users"},
+		{"https://api.example.com/v2/", "users", "https://api.example.com/v2/users"},
+		{"https://api.example.com/v2", "", "https://api.example.com/v2"},
+		{"https://api.example.com/v2", "?page=2", "https://api.example.com/v2?page=2"},
+		{"https://api.example.com/v2", "http://other.com/users", "http://other.com/users"},
+	}
+	for _, c := range cases {
+		if got := resolveURL(c.base, c.target); got != c.want {
+			t.Error(fmt.Sprintf("Expected %q + %q -> %q | but got %q", c.base, c.target, c.want, got))
+		}
+	}
+}
+
+func TestClientDefaults(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, password, _ := r.BasicAuth()
+		fmt.Fprintf(w, "%s %s %s %s:%s %s", r.Method, r.URL.Path, r.Header.Get("Accept"), user, password, r.URL.RawQuery)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL+"/v2").
+		Set("Accept", "application/json").
+		Param("api_key", "k").
+		SetBasicAuth("user", "pass").
+		Timeout(5 * time.Second)
+
+	_, body, errs := client.Get("/users/{id}").PathParam("id", 1).End()
+	if errs != nil {
+		t.Fatal(errs)
+	}
+	if want := "GET /v2/users/1 application/json user:pass api_key=k"; body != want {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", want, body))
+	}
+
+	// overrides only apply to one request
+	_, body, _ = client.Post("/users").Set("Accept", "text/plain").Param("page", "2").End()
+	if want := "POST /v2/users text/plain user:pass api_key=k&page=2"; body != want {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", want, body))
+	}
+	_, body, _ = client.Get("/users").Param("api_key", "other").Param("api_key", "more").End()
+	if want := "GET /v2/users application/json user:pass api_key=other&api_key=more"; body != want {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", want, body))
+	}
+	_, body, _ = client.Get("/users?api_key=url").End()
+	if want := "GET /v2/users application/json user:pass api_key=url"; body != want {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", want, body))
+	}
+	_, body, _ = client.Delete("users/1").End()
+	if want := "DELETE /v2/users/1 application/json user:pass api_key=k"; body != want {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", want, body))
+	}
+
+	// base url on a SuperAgent survives ClearSuperAgent
+	request := New().BaseURL(ts.URL)
+	request.Get("/a").End()
+	_, body, _ = request.Get("/b").End()
+	if want := "GET /b  : "; body != want {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", want, body))
+	}
+}
+
+func TestClientRequestReuse(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s %s %s %s", r.Method, r.URL.RequestURI(), r.Header.Get("Accept"), r.Header.Get("X-Once"), r.Header.Get("Content-Type"), body)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL).Set("Accept", "application/json").Param("v", "1")
+	request := client.Post("/a").Set("X-Once", "1").Param("page", "2").Send(`{"a":1}`)
+	if _, body, _ := request.End(); body != `POST /a?page=2&v=1 application/json 1 application/json {"a":1}` {
+		t.Error(fmt.Sprintf("Expected the first request | but got %q", body))
+	}
+	// the next request starts from the Client defaults, not from the previous request
+	if _, body, _ := request.Get("/b").End(); body != "GET /b?v=1 application/json   " {
+		t.Error(fmt.Sprintf("Expected only the Client defaults | but got %q", body))
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	client := NewClient("http://localhost").Retry(3, time.Millisecond, 999)
+	if len(client.Errors()) != 1 {
+		t.Error(fmt.Sprintf("Expected 1 client error | but got %v", client.Errors()))
+	}
+	request := client.Get("/")
+	if _, _, errs := request.End(); len(errs) != 1 {
+		t.Error(fmt.Sprintf("Expected client error to be returned by End | but got %v", errs))
+	}
+	// and by the next requests of a reused request
+	if _, _, errs := request.Post("/").End(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "'999'") {
+		t.Error(fmt.Sprintf("Expected client error to be returned again | but got %v", errs))
+	}
+}
+
+func TestClientImmutable(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Header.Get("X-Version"))
+	}))
+	defer ts.Close()
+
+	v1 := NewClient(ts.URL).Set("X-Version", "1")
+	v2 := v1.Set("X-Version", "2")
+	insecure := v1.TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+
+	if _, body, _ := v1.Get("/").End(); body != "1" {
+		t.Error(fmt.Sprintf("Expected X-Version 1 | but got %q", body))
+	}
+	if _, body, _ := v2.Get("/").End(); body != "2" {
+		t.Error(fmt.Sprintf("Expected X-Version 2 | but got %q", body))
+	}
+	if v1.agent.Transport != v2.agent.Transport {
+		t.Error("Expected derived clients to share their transport")
+	}
+	if v1.agent.Transport == insecure.agent.Transport || !insecure.agent.Transport.TLSClientConfig.InsecureSkipVerify ||
+		(v1.agent.Transport.TLSClientConfig != nil && v1.agent.Transport.TLSClientConfig.InsecureSkipVerify) {
+		t.Error("Expected TLSClientConfig to fork the transport")
+	}
+}
+
+func TestClientConcurrently(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Query().Get("i"), r.Header.Get("X-Worker"))
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL).Set("X-Worker", "base").Timeout(5 * time.Second)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := client
+			if i%2 == 0 {
+				c = client.Set("X-Worker", "even").Proxy("")
+			}
+			_, body, errs := c.Get("/").Param("i", strconv.Itoa(i)).End()
+			want := strconv.Itoa(i) + "base"
+			if i%2 == 0 {
+				want = strconv.Itoa(i) + "even"
+			}
+			if errs != nil || body != want {
+				t.Error(fmt.Sprintf("Expected body %q | but got %q, %v", want, body, errs))
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,284 @@
+// Command gorequest sends HTTP requests from the command line with the gorequest library,
+// going through the same encoding as the services using it:
+//
+//	gorequest [flags] [METHOD] URL [ITEM...]
+//
+// Items build the request:
+//
+//	name=value      JSON field, or form field with --form or --multipart
+//	name:=json      raw JSON field, such as count:=3 or tags:='["a","b"]'
+//	name==value     query parameter
+//	Header:value    header
+//	field@path      file upload, implies --multipart
+//
+// The method defaults to GET, or POST if the request has a body. URLs default to http,
+// and ":3000/path" is short for http://localhost:3000/path. Flags come before the method:
+//
+//	gorequest --retry 3 POST api.example.com/users name=gopher age:=7 Authorization:'Bearer token'
+//	gorequest --curl :8080/search q==gopher
+//	gorequest --bench 1000 --concurrency 10 :8080/health
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// retryStatuses are the statuses retried with --retry.
+var retryStatuses = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+var method = regexp.MustCompile(`^[A-Z]+$`)
+
+// run runs the command with args, and returns its exit status.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gorequest", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var (
+		form      = flags.Bool("form", false, "send the fields as an urlencoded form")
+		multipart = flags.Bool("multipart", false, "send the fields as a multipart form")
+		retry     = flags.Int("retry", 0, "retry `n` times on 429, 502, 503 and 504 responses")
+		retryWait = flags.Duration("retry-wait", time.Second, "wait `duration` between retries")
+		timeout   = flags.Duration("timeout", 0, "fail after `duration`, 0 for no timeout")
+		proxy     = flags.String("proxy", "", "send the request through the proxy `url`")
+		insecure  = flags.Bool("insecure", false, "skip the verification of TLS certificates")
+		curl      = flags.Bool("curl", false, "print the request as a curl command instead of sending it")
+		redact    = flags.Bool("redact", false, "hide the secrets of the request printed with --curl")
+		debug     = flags.Bool("debug", false, "log the request and the response")
+		bodyOnly  = flags.Bool("body", false, "print the response body only")
+		bench     = flags.Int("bench", 0, "send the request `n` times and print a load report")
+		workers   = flags.Int("concurrency", 1, "send `n` requests at a time with --bench")
+		rate      = flags.Float64("rate", 0, "send `n` requests per second for --duration and print a load report")
+		duration  = flags.Duration("duration", 10*time.Second, "send requests for `duration` with --rate")
+		inFlight  = flags.Int("max-in-flight", 100, "send at most `n` requests at a time with --rate, dropping the others")
+	)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gorequest [flags] [METHOD] URL [name=value | name:=json | name==value | Header:value | field@path]...")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	request, err := build(args, *form, *multipart)
+	if err != nil {
+		fmt.Fprintf(stderr, "gorequest: %v\n", err)
+		return 2
+	}
+	request.SetDebug(*debug)
+	if *debug {
+		request.SetLogger(log.New(stderr, "[gorequest]", log.LstdFlags))
+	}
+	if *retry > 0 {
+		request.Retry(*retry, *retryWait, retryStatuses...)
+	}
+	if *timeout > 0 {
+		request.Timeout(*timeout)
+	}
+	if *proxy != "" {
+		request.Proxy(*proxy)
+	}
+	if *insecure {
+		request.TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+	}
+
+	if *curl {
+		// the command is printed to be run, secrets included unless asked otherwise
+		if !*redact {
+			request.SetRedaction(gorequest.RedactionPolicy{})
+		}
+		cmd, err := request.Export(gorequest.CurlExporter)
+		if err != nil {
+			fmt.Fprintf(stderr, "gorequest: %v\n", err)
+			return 1
+		}
+		fmt.Fprintln(stdout, cmd)
+		return 0
+	}
+
+	switch {
+	case *bench > 0:
+		fmt.Fprint(stdout, request.Bench(*bench, *workers))
+		return 0
+	case *rate > 0:
+		fmt.Fprint(stdout, request.RunLoad(*rate, *duration, *inFlight))
+		return 0
+	}
+
+	resp, body, errs := request.EndBytes()
+	if errs != nil {
+		for _, err := range errs {
+			fmt.Fprintf(stderr, "gorequest: %v\n", err)
+		}
+		return 1
+	}
+	if !*bodyOnly {
+		fmt.Fprintf(stdout, "%s %s\n", resp.Proto, resp.Status)
+		names := make([]string, 0, len(resp.Header))
+		for name := range resp.Header {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, value := range resp.Header[name] {
+				fmt.Fprintf(stdout, "%s: %s\n", name, value)
+			}
+		}
+		fmt.Fprintln(stdout)
+	}
+	stdout.Write(pretty(resp.Header.Get("Content-Type"), body))
+	return 0
+}
+
+// build returns the request described by the arguments following the flags.
+func build(args []string, form, multipart bool) (*gorequest.SuperAgent, error) {
+	requestMethod := ""
+	if len(args) > 1 && method.MatchString(args[0]) {
+		requestMethod, args = args[0], args[1:]
+	}
+	target, items := expandURL(args[0]), args[1:]
+
+	var (
+		fields  = map[string]interface{}{}
+		nested  []string
+		headers [][2]string
+		query   [][2]string
+		files   [][2]string
+	)
+	for _, item := range items {
+		// the separator found first splits the item, == and := winning over = and :
+		sep := strings.IndexAny(item, "=:@")
+		if sep <= 0 {
+			return nil, fmt.Errorf("invalid item %q", item)
+		}
+		name, rest := item[:sep], item[sep:]
+		switch {
+		case strings.HasPrefix(rest, "=="):
+			query = append(query, [2]string{name, rest[2:]})
+		case strings.HasPrefix(rest, ":="):
+			// numbers are kept as written, float64 would round large integers
+			decoder := json.NewDecoder(strings.NewReader(rest[2:]))
+			decoder.UseNumber()
+			var value interface{}
+			if err := decoder.Decode(&value); err != nil {
+				return nil, fmt.Errorf("invalid JSON in item %q: %v", item, err)
+			}
+			if decoder.More() {
+				return nil, fmt.Errorf("invalid JSON in item %q: trailing data", item)
+			}
+			if !formValue(value) {
+				nested = append(nested, item)
+			}
+			fields[name] = value
+		case rest[0] == '=':
+			fields[name] = rest[1:]
+		case rest[0] == ':':
+			headers = append(headers, [2]string{name, strings.TrimSpace(rest[1:])})
+		case rest[0] == '@':
+			files = append(files, [2]string{name, rest[1:]})
+		}
+	}
+
+	if requestMethod == "" {
+		requestMethod = gorequest.GET
+		if len(fields) != 0 || len(files) != 0 {
+			requestMethod = gorequest.POST
+		}
+	}
+	request := gorequest.New().CustomMethod(requestMethod, target)
+	for _, header := range headers {
+		request.AppendHeader(header[0], header[1])
+	}
+	for _, param := range query {
+		request.Param(param[0], param[1])
+	}
+	if (form || multipart || len(files) != 0) && len(nested) != 0 {
+		// forms hold no objects, which would be left out of the body
+		return nil, fmt.Errorf("item %q can't be sent in a form", nested[0])
+	}
+	switch {
+	case multipart || len(files) != 0:
+		request.Type(gorequest.TypeMultipart)
+	case form:
+		request.Type(gorequest.TypeForm)
+	}
+	if len(fields) != 0 {
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		request.SendString(string(data))
+	}
+	for _, file := range files {
+		request.SendFile(file[1], "", file[0], true)
+	}
+	if len(request.Errors) != 0 {
+		return nil, request.Errors[0]
+	}
+	return request, nil
+}
+
+// formValue tells whether a := value fits in a form field: forms hold values and lists
+// of values, but no objects nor nulls.
+func formValue(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}, nil:
+		return false
+	case []interface{}:
+		for _, element := range v {
+			switch element.(type) {
+			case map[string]interface{}, []interface{}, nil:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// expandURL adds the http scheme to target, and localhost to ":port/path" targets.
+func expandURL(target string) string {
+	if strings.HasPrefix(target, ":") {
+		target = "localhost" + target
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	return target
+}
+
+// pretty indents JSON bodies, and ends the body with a newline.
+func pretty(contentType string, body []byte) []byte {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var buf bytes.Buffer
+		if json.Indent(&buf, body, "", "  ") == nil {
+			body = buf.Bytes()
+		}
+	}
+	if len(body) != 0 && body[len(body)-1] != '\n' {
+		body = append(body, '\n')
+	}
+	return body
+}
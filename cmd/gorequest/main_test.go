@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			r.ParseMultipartForm(1 << 20)
+			file, header, _ := r.FormFile("avatar")
+			data, _ := ioutil.ReadAll(file)
+			fmt.Fprintf(w, "%s name=%s %s=%s", r.Method, r.FormValue("name"), header.Filename, data)
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"method":%q,"uri":%q,"auth":%q,"type":%q,"body":%q}`,
+			r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"), r.Header.Get("Content-Type"), body)
+	}))
+	defer ts.Close()
+	port := ts.URL[strings.LastIndexByte(ts.URL, ':'):]
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"--body", port + "/users", "name=gopher", "age:=7", "q==a b", "Authorization:Bearer token"}, &stdout, &stderr)
+	expected := `{
+  "method": "POST",
+  "uri": "/users?q=a+b",
+  "auth": "Bearer token",
+  "type": "application/json",
+  "body": "{\"age\":7,\"name\":\"gopher\"}"
+}
+`
+	if status != 0 || stdout.String() != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %d %s %s", expected, status, stdout.String(), stderr.String()))
+	}
+
+	// large integers aren't rounded
+	stdout.Reset()
+	status = run([]string{"--body", port + "/ids", "id:=9007199254740993", "ratio:=0.1"}, &stdout, &stderr)
+	if !strings.Contains(stdout.String(), `"body": "{\"id\":9007199254740993,\"ratio\":0.1}"`) {
+		t.Error(fmt.Sprintf("Expected the numbers as written | but got %d %s %s", status, stdout.String(), stderr.String()))
+	}
+
+	stdout.Reset()
+	status = run([]string{"--form", "PUT", ts.URL, "name=gopher", "tags:=[\"a\",\"b\"]"}, &stdout, &stderr)
+	if status != 0 || !strings.HasPrefix(stdout.String(), "HTTP/1.1 200 OK\nContent-Length: ") ||
+		!strings.Contains(stdout.String(), `"type": "application/x-www-form-urlencoded"`) ||
+		!strings.Contains(stdout.String(), `"body": "name=gopher&tags=a&tags=b"`) {
+		t.Error(fmt.Sprintf("Expected a form | but got %d %s", status, stdout.String()))
+	}
+
+	filename := filepath.Join(t.TempDir(), "avatar.png")
+	ioutil.WriteFile(filename, []byte("png"), 0644)
+	stdout.Reset()
+	status = run([]string{"--body", ts.URL, "name=gopher", "avatar@" + filename}, &stdout, &stderr)
+	if expected := "POST name=gopher avatar.png=png\n"; status != 0 || stdout.String() != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %d %s", expected, status, stdout.String()))
+	}
+}
+
+func TestRunCurl(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"--curl", "--form", "example.com/login", "user=gopher", "X-Api-Key:secret"}, &stdout, &stderr)
+	expected := "curl -X 'POST' -d 'user=gopher' -H 'Content-Type: application/x-www-form-urlencoded' -H 'X-Api-Key: secret' 'http://example.com/login'\n"
+	if status != 0 || stdout.String() != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %d %s %s", expected, status, stdout.String(), stderr.String()))
+	}
+
+	stdout.Reset()
+	status = run([]string{"--curl", "--redact", "example.com/login", "X-Api-Key:secret"}, &stdout, &stderr)
+	expected = "curl -X 'GET' -H 'X-Api-Key: [REDACTED]' 'http://example.com/login'\n"
+	if status != 0 || stdout.String() != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %d %s %s", expected, status, stdout.String(), stderr.String()))
+	}
+}
+
+func TestRunBench(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	var stdout, stderr bytes.Buffer
+	status := run([]string{"--bench", "20", "--concurrency", "4", ts.URL}, &stdout, &stderr)
+	if status != 0 || !strings.HasPrefix(stdout.String(), "Requests:   20 in ") || !strings.Contains(stdout.String(), "Statuses:   200: 20\n") {
+		t.Error(fmt.Sprintf("Expected a load report | but got %d %s %s", status, stdout.String(), stderr.String()))
+	}
+
+	stdout.Reset()
+	status = run([]string{"--rate", "100", "--duration", "50ms", ts.URL}, &stdout, &stderr)
+	if status != 0 || !strings.Contains(stdout.String(), "Latency:    min ") {
+		t.Error(fmt.Sprintf("Expected a load report | but got %d %s %s", status, stdout.String(), stderr.String()))
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{{}, {"--unknown"}, {"example.com", "invalid"}, {"example.com", "a:=not json"}, {"example.com", "f@missing.txt"},
+		{"--form", "example.com", `user:={"name":"gopher"}`}, {"--multipart", "example.com", `ids:=[1,{"a":2}]`}} {
+		var stdout, stderr bytes.Buffer
+		if status := run(args, &stdout, &stderr); status != 2 || stderr.Len() == 0 {
+			t.Error(fmt.Sprintf("Expected status 2 and an error for %q | but got %d %s", args, status, stderr.String()))
+		}
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"--timeout", "1ms", "http://127.0.0.1:1"}, &stdout, &stderr); status != 1 || !strings.HasPrefix(stderr.String(), "gorequest: ") {
+		t.Error(fmt.Sprintf("Expected status 1 and a connection error | but got %d %s", status, stderr.String()))
+	}
+}
@@ -0,0 +1,425 @@
+package gorequest
+
+import (
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// curlIgnoredFlags are the curl flags without argument which don't change the request.
+var curlIgnoredFlags = map[string]bool{
+	"-s": true, "--silent": true, "-S": true, "--show-error": true, "-v": true, "--verbose": true,
+	"-i": true, "--include": true, "-L": true, "--location": true, "-f": true, "--fail": true,
+	"-#": true, "--progress-bar": true, "--http1.1": true, "--http2": true,
+}
+
+// curlShortFlags are the short curl flags without argument, which may be grouped.
+const curlShortFlags = "sSviLf#IGk"
+
+// curlIgnoredOptions are the curl options with an argument which don't change the request.
+var curlIgnoredOptions = map[string]bool{
+	"-o": true, "--output": true, "-w": true, "--write-out": true, "--connect-timeout": true,
+	"--max-redirs": true, "-D": true, "--dump-header": true,
+}
+
+// FromCurl returns a SuperAgent sending the request of a curl command, as copied from
+// the developer tools of browsers:
+//
+//	request, err := gorequest.FromCurl(`curl 'https://api.example.com/users' \
+//	  -H 'Authorization: Bearer token' \
+//	  --data-raw '{"name":"gopher"}'`)
+//	if err != nil {
+//	  return err
+//	}
+//	resp, body, errs := request.End()
+//
+// It understands -X, -H, -d, --data-raw, --data-binary, --data-urlencode, -G, -F, -u, -b,
+// -A, -e, -I, -m, --compressed, -k and --proxy. Options only changing curl's output, such
+// as -s or -o, are ignored, the other ones are errors. Data is sent byte for byte as curl
+// does, as an urlencoded form unless a Content-Type header is given.
+func FromCurl(cmd string) (*SuperAgent, error) {
+	args, err := splitShellWords(cmd)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, errors.New("gorequest: not a curl command")
+	}
+
+	var (
+		method, target string
+		data           []string
+		form           bool
+		get            bool
+		compressed     bool
+		// options are applied once the method is known, as it clears the SuperAgent
+		options []func(s *SuperAgent) error
+	)
+	option := func(apply func(s *SuperAgent)) {
+		options = append(options, func(s *SuperAgent) error { apply(s); return nil })
+	}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if target != "" {
+				return nil, errors.Errorf("gorequest: curl command with several urls %q and %q", target, arg)
+			}
+			target = arg
+			continue
+		}
+		name, value, hasValue := arg, "", false
+		// short options may be grouped (-sS) or followed by their value (-XPOST)
+		if !strings.HasPrefix(arg, "--") && len(arg) > 2 {
+			if strings.Trim(arg[1:], curlShortFlags) == "" {
+				expanded := make([]string, 0, len(args)+len(arg))
+				expanded = append(expanded, args[:i]...)
+				for _, c := range arg[1:] {
+					expanded = append(expanded, "-"+string(c))
+				}
+				args = append(expanded, args[i+1:]...)
+				i--
+				continue
+			}
+			name, value, hasValue = arg[:2], arg[2:], true
+		}
+		if curlIgnoredFlags[name] {
+			continue
+		}
+		switch name {
+		case "-I", "--head":
+			method = http.MethodHead
+			continue
+		case "-G", "--get":
+			get = true
+			continue
+		case "-k", "--insecure":
+			option(func(s *SuperAgent) { s.TLSClientConfig(&tls.Config{InsecureSkipVerify: true}) })
+			continue
+		case "--compressed":
+			compressed = true
+			continue
+		}
+
+		if !hasValue {
+			if i+1 == len(args) {
+				return nil, errors.Errorf("gorequest: curl option %s without value", name)
+			}
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "--url":
+			target = value
+		case "-X", "--request":
+			method = strings.ToUpper(value)
+		case "-H", "--header":
+			options = append(options, func(s *SuperAgent) error { return addCurlHeader(s, value) })
+		case "-A", "--user-agent":
+			option(func(s *SuperAgent) { s.Set("User-Agent", value) })
+		case "-e", "--referer":
+			option(func(s *SuperAgent) { s.Set("Referer", value) })
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw":
+			if strings.HasPrefix(value, "@") && name != "--data-raw" {
+				content, err := ioutil.ReadFile(value[1:])
+				if err != nil {
+					return nil, err
+				}
+				value = string(content)
+				if name != "--data-binary" {
+					// like curl, -d strips the newlines of files
+					value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+				}
+			}
+			data = append(data, value)
+		case "--data-urlencode":
+			data = append(data, curlURLEncode(value))
+		case "-F", "--form":
+			form = true
+			options = append(options, func(s *SuperAgent) error { return addCurlFormField(s, value) })
+		case "-u", "--user":
+			username, password := value, ""
+			if i := strings.IndexByte(value, ':'); i >= 0 {
+				username, password = value[:i], value[i+1:]
+			}
+			option(func(s *SuperAgent) { s.SetBasicAuth(username, password) })
+		case "-b", "--cookie":
+			if !strings.Contains(value, "=") {
+				return nil, errors.Errorf("gorequest: curl cookie files aren't supported: %s", value)
+			}
+			for _, pair := range strings.Split(value, ";") {
+				if pair = strings.TrimSpace(pair); pair == "" {
+					continue
+				}
+				cookie := strings.SplitN(pair, "=", 2)
+				if len(cookie) != 2 {
+					return nil, errors.Errorf("gorequest: invalid curl cookie %q", pair)
+				}
+				option(func(s *SuperAgent) { s.AddCookie(&http.Cookie{Name: cookie[0], Value: cookie[1]}) })
+			}
+		case "-x", "--proxy":
+			if !strings.Contains(value, "://") {
+				value = "http://" + value
+			}
+			option(func(s *SuperAgent) { s.Proxy(value) })
+		case "-m", "--max-time":
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return nil, errors.Errorf("gorequest: invalid curl max time %q", value)
+			}
+			option(func(s *SuperAgent) { s.Timeout(time.Duration(seconds * float64(time.Second))) })
+		default:
+			if !curlIgnoredOptions[name] {
+				return nil, errors.Errorf("gorequest: unsupported curl option %s", name)
+			}
+		}
+	}
+
+	if target == "" {
+		return nil, errors.New("gorequest: curl command without url")
+	}
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	if form && len(data) != 0 {
+		return nil, errors.New("gorequest: curl command with both -F and -d")
+	}
+	if method == "" {
+		method = http.MethodGet
+		if (len(data) != 0 && !get) || form {
+			method = http.MethodPost
+		}
+	}
+	s := New().CustomMethod(method, target)
+	for _, apply := range options {
+		if err := apply(s); err != nil {
+			return nil, err
+		}
+	}
+	if compressed {
+		// the transport negotiates gzip and decompresses the response itself, which it
+		// doesn't if the Accept-Encoding header is set
+		s.Header.Del("Accept-Encoding")
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case form:
+		s.Type(TypeMultipart)
+	case get && len(data) != 0:
+		s.Query(body)
+	case len(data) != 0:
+		// curl sends the data as it is, as an urlencoded form unless told otherwise
+		if s.Header.Get("Content-Type") == "" {
+			s.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		s.BounceToRawString = true
+		s.Type(TypeText).SendString(body)
+	}
+	if len(s.Errors) != 0 {
+		return nil, s.Errors[0]
+	}
+	return s, nil
+}
+
+// addCurlHeader adds a -H header: "Name: value", or "Name;" for an empty value.
+// curl removes the headers without value, "Name:", which are ignored.
+func addCurlHeader(s *SuperAgent, header string) error {
+	if strings.HasSuffix(header, ";") && !strings.Contains(header, ":") {
+		s.AppendHeader(strings.TrimSuffix(header, ";"), "")
+		return nil
+	}
+	i := strings.IndexByte(header, ':')
+	if i <= 0 {
+		return errors.Errorf("gorequest: invalid curl header %q", header)
+	}
+	if value := strings.TrimSpace(header[i+1:]); value != "" {
+		s.AppendHeader(strings.TrimSpace(header[:i]), value)
+	}
+	return nil
+}
+
+// addCurlFormField adds a -F field: "name=value", "name=@file" for a file, or "name=<file"
+// for a field read from a file. Files accept a ";filename=" parameter.
+func addCurlFormField(s *SuperAgent, field string) error {
+	i := strings.IndexByte(field, '=')
+	if i <= 0 {
+		return errors.Errorf("gorequest: invalid curl form field %q", field)
+	}
+	name, value := field[:i], field[i+1:]
+	switch {
+	case strings.HasPrefix(value, "@"):
+		params := strings.Split(value[1:], ";")
+		filename := ""
+		for _, param := range params[1:] {
+			if strings.HasPrefix(param, "filename=") {
+				filename = strings.Trim(strings.TrimPrefix(param, "filename="), `"`)
+			}
+		}
+		s.SendFile(params[0], filename, name, true)
+	case strings.HasPrefix(value, "<"):
+		content, err := ioutil.ReadFile(strings.Split(value[1:], ";")[0])
+		if err != nil {
+			return err
+		}
+		s.FormData.Add(name, string(content))
+	default:
+		s.FormData.Add(name, value)
+	}
+	return nil
+}
+
+// curlURLEncode encodes a --data-urlencode value: "content", "=content" or "name=content".
+func curlURLEncode(value string) string {
+	i := strings.IndexByte(value, '=')
+	switch {
+	case i < 0:
+		return url.QueryEscape(value)
+	case i == 0:
+		return url.QueryEscape(value[1:])
+	}
+	return value[:i] + "=" + url.QueryEscape(value[i+1:])
+}
+
+// splitShellWords splits a POSIX shell command line into words, handling single quotes,
+// double quotes, $'...' quotes, backslash escapes and line continuations.
+func splitShellWords(line string) ([]string, error) {
+	var (
+		words  []string
+		word   strings.Builder
+		inWord bool
+	)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case c == '\\':
+			inWord = true
+			if i+1 < len(line) {
+				i++
+				if line[i] == '\n' {
+					// line continuation
+					inWord = word.Len() != 0
+					continue
+				}
+				word.WriteByte(line[i])
+			}
+		case c == '\'':
+			inWord = true
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("gorequest: unterminated ' quote")
+			}
+			word.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+		case c == '"':
+			inWord = true
+			for i++; ; i++ {
+				if i == len(line) {
+					return nil, errors.New(`gorequest: unterminated " quote`)
+				}
+				if line[i] == '"' {
+					break
+				}
+				if line[i] == '\\' && i+1 < len(line) && strings.IndexByte("\"\\$`\n", line[i+1]) >= 0 {
+					i++
+					if line[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(line[i])
+			}
+		case c == '$' && i+1 < len(line) && line[i+1] == '\'':
+			inWord = true
+			n, err := unquoteANSIC(line[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			i += n + 1
+		default:
+			inWord = true
+			word.WriteByte(c)
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// unquoteANSIC writes the content of a $'...' quote, starting after the opening quote,
+// to word, and returns the length of the content with the closing quote.
+func unquoteANSIC(s string, word *strings.Builder) (int, error) {
+	simple := map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', 'a': '\a', 'b': '\b', 'e': 0x1b,
+		'f': '\f', 'v': '\v', '\\': '\\', '\'': '\'', '"': '"', '?': '?'}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\'':
+			return i + 1, nil
+		case '\\':
+			if i+1 == len(s) {
+				break
+			}
+			i++
+			if c, ok := simple[s[i]]; ok {
+				word.WriteByte(c)
+				continue
+			}
+			digits, base, size := 0, 16, 0
+			switch s[i] {
+			case 'x':
+				digits, size = 2, 1
+			case 'u':
+				digits, size = 4, 4
+			case 'U':
+				digits, size = 8, 4
+			default:
+				if s[i] >= '0' && s[i] <= '7' {
+					i--
+					digits, base, size = 3, 8, 1
+				}
+			}
+			if digits == 0 {
+				word.WriteByte('\\')
+				word.WriteByte(s[i])
+				continue
+			}
+			end := i + 1
+			for end < len(s) && end < i+1+digits && isDigitOf(s[end], base) {
+				end++
+			}
+			code, err := strconv.ParseUint(s[i+1:end], base, 32)
+			if err != nil {
+				return 0, errors.Errorf("gorequest: invalid escape in $'...' quote: %q", s[i:end])
+			}
+			if size == 1 {
+				word.WriteByte(byte(code))
+			} else {
+				var buf [utf8.UTFMax]byte
+				word.Write(buf[:utf8.EncodeRune(buf[:], rune(code))])
+			}
+			i = end - 1
+		default:
+			word.WriteByte(s[i])
+		}
+	}
+	return 0, errors.New("gorequest: unterminated $' quote")
+}
+
+func isDigitOf(c byte, base int) bool {
+	if base == 8 {
+		return c >= '0' && c <= '7'
+	}
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
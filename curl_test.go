@@ -0,0 +1,131 @@
+package gorequest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	words, err := splitShellWords("curl 'a b' \"c \\\"d\\\" \\$e\" f\\ g \\\n  $'h\\ni\\x41\\u00e9\\'' j''k")
+	expected := []string{"curl", "a b", `c "d" $e`, "f g", "h\niAé'", "jk"}
+	if err != nil || !reflect.DeepEqual(words, expected) {
+		t.Error(fmt.Sprintf("Expected %q | but got %q, %v", expected, words, err))
+	}
+	for _, line := range []string{"curl 'a", `curl "a`, "curl $'a"} {
+		if _, err := splitShellWords(line); err == nil {
+			t.Error(fmt.Sprintf("Expected an unterminated quote error for %s | but got none", line))
+		}
+	}
+}
+
+func TestFromCurl(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		username, password, _ := r.BasicAuth()
+		fmt.Fprintf(w, "%s %s?%s|%s|%s|%s:%s|%s|%s", r.Method, r.URL.Path, r.URL.RawQuery,
+			r.Header.Get("Content-Type"), r.Header.Get("X-Empty"), username, password, r.Header.Get("Cookie"), body)
+	}))
+	defer ts.Close()
+
+	request, err := FromCurl(`curl '` + ts.URL + `/users?page=1' \
+  -H 'Content-Type: application/json' \
+  -H 'X-Empty;' \
+  -b 'a=1; b=2' \
+  -u 'user:pass' \
+  --data-raw '{"name":"gopher"}' \
+  --compressed -sS`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, errs := request.End()
+	expected := `POST /users?page=1|application/json||user:pass|a=1; b=2|{"name":"gopher"}`
+	if errs != nil || body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s, %v", expected, body, errs))
+	}
+
+	request, _ = FromCurl(`curl -XPUT ` + ts.URL + `/form -d a=1 -d b=2`)
+	_, body, _ = request.End()
+	if expected := "PUT /form?|application/x-www-form-urlencoded||:||a=1&b=2"; body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, body))
+	}
+
+	// data is sent as it is, whatever it holds
+	request, _ = FromCurl(`curl ` + ts.URL + `/echo -d 'hello world'`)
+	_, body, _ = request.End()
+	if expected := "POST /echo?|application/x-www-form-urlencoded||:||hello world"; body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, body))
+	}
+	request, _ = FromCurl(`curl ` + ts.URL + `/echo -d '{"b":1,"a":2}'`)
+	_, body, _ = request.End()
+	if expected := `POST /echo?|application/x-www-form-urlencoded||:||{"b":1,"a":2}`; body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, body))
+	}
+	request, _ = FromCurl(`curl ` + ts.URL + `/echo -H 'Content-Type: application/json' --data-binary '{"b":1, "a":[2]}'`)
+	_, body, _ = request.End()
+	if expected := `POST /echo?|application/json||:||{"b":1, "a":[2]}`; body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, body))
+	}
+
+	request, _ = FromCurl(`curl -G ` + ts.URL + `/search --data-urlencode 'q=a b'`)
+	_, body, _ = request.End()
+	if expected := "GET /search?q=a+b|||:||"; body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, body))
+	}
+
+	request, _ = FromCurl(`curl -X PATCH ` + ts.URL + ` -H 'Content-Type: text/csv' --data-binary $'a,b\n1,2\n'`)
+	_, body, _ = request.End()
+	if expected := "PATCH /?|text/csv||:||a,b\n1,2\n"; body != expected {
+		t.Error(fmt.Sprintf("Expected %q | but got %q", expected, body))
+	}
+}
+
+func TestFromCurlMultipart(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Error(err)
+			return
+		}
+		file, header, _ := r.FormFile("upload")
+		data, _ := ioutil.ReadAll(file)
+		fmt.Fprintf(w, "%s %s %s %s", r.Method, r.FormValue("name"), header.Filename, data)
+	}))
+	defer ts.Close()
+
+	filename := filepath.Join(t.TempDir(), "data.txt")
+	ioutil.WriteFile(filename, []byte("content"), 0644)
+	request, err := FromCurl(`curl ` + ts.URL + ` -F name=gopher -F 'upload=@` + filename + `;filename=renamed.txt'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, errs := request.End()
+	if expected := "POST gopher renamed.txt content"; errs != nil || body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s, %v", expected, body, errs))
+	}
+}
+
+func TestFromCurlOptions(t *testing.T) {
+	request, err := FromCurl(`curl -k -m 2.5 --proxy localhost:3128 -A agent -I example.com`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Method != HEAD || request.Url != "http://example.com" || request.Header.Get("User-Agent") != "agent" {
+		t.Error(fmt.Sprintf("Expected HEAD http://example.com | but got %s %s %v", request.Method, request.Url, request.Header))
+	}
+	if !request.Transport.TLSClientConfig.InsecureSkipVerify || request.proxyURL != "http://localhost:3128" || request.Client.Timeout != 2500*time.Millisecond {
+		t.Error(fmt.Sprintf("Expected insecure TLS, proxy and timeout | but got %v, %s, %v", request.Transport.TLSClientConfig, request.proxyURL, request.Client.Timeout))
+	}
+
+	for _, cmd := range []string{"wget example.com", "curl", "curl -H", "curl --unknown example.com", "curl -b cookies.txt example.com",
+		"curl a.com b.com", "curl -F a=b -d c example.com"} {
+		if _, err := FromCurl(cmd); err == nil || !strings.HasPrefix(err.Error(), "gorequest: ") {
+			t.Error(fmt.Sprintf("Expected an error for %s | but got %v", cmd, err))
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// Color modes of DebugOptions.
+const (
+	ColorNever = iota
+	// ColorAuto colorizes if the Logger is a *log.Logger writing to a terminal.
+	ColorAuto
+	ColorAlways
+)
+
+// DebugOptions tune the output of SetDebug, see SetDebugOptions.
+type DebugOptions struct {
+	// Pretty indents JSON and XML bodies.
+	Pretty bool
+	// MaxBodySize truncates longer bodies, 0 for no limit.
+	MaxBodySize int
+	// Color is ColorNever, ColorAuto or ColorAlways. It only applies to the Logger,
+	// not to the structured logger.
+	Color int
+}
+
+// DefaultDebugOptions are the options used unless SetDebugOptions is called. Bodies are
+// printed whole unless MaxBodySize is set.
+var DefaultDebugOptions = DebugOptions{}
+
+// SetDebugOptions sets how debug mode prints requests and responses. Whatever the options,
+// binary bodies and multipart parts are summarized by their name and size:
+//
+//	gorequest.New().
+//	  SetDebug(true).
+//	  SetDebugOptions(gorequest.DebugOptions{Pretty: true, MaxBodySize: 4096, Color: gorequest.ColorAuto})
+func (s *SuperAgent) SetDebugOptions(options DebugOptions) *SuperAgent {
+	s.debugOptions = &options
+	return s
+}
+
+// SetDebugOptions returns a Client with debug options, see SuperAgent.SetDebugOptions.
+func (c *Client) SetDebugOptions(options DebugOptions) *Client {
+	return c.with(func(s *SuperAgent) { s.SetDebugOptions(options) })
+}
+
+// debugFormatter returns the formatter of the debug output.
+func (s *SuperAgent) debugFormatter() *debugFormatter {
+	options := DefaultDebugOptions
+	if s.debugOptions != nil {
+		options = *s.debugOptions
+	}
+	f := &debugFormatter{DebugOptions: options}
+	switch options.Color {
+	case ColorAlways:
+		f.color = s.slogger == nil
+	case ColorAuto:
+		f.color = s.slogger == nil && isTerminal(s.logger)
+	}
+	return f
+}
+
+// dumpRequest returns the debug output of req, without secrets.
+func (s *SuperAgent) dumpRequest(req *http.Request) (string, error) {
+	r, err := s.redaction().redactRequest(req)
+	if err != nil {
+		return "", err
+	}
+	head, err := httputil.DumpRequest(r, false)
+	if err != nil {
+		return "", err
+	}
+	body, err := readBody(&r.Body)
+	if err != nil {
+		return "", err
+	}
+	return s.debugFormatter().format(head, r.Header.Get("Content-Type"), body, false), nil
+}
+
+// dumpResponse returns the debug output of resp, without secrets.
+func (s *SuperAgent) dumpResponse(resp *http.Response, body []byte) (string, error) {
+	r := s.redaction().redactResponse(resp, body)
+	body, _ = readBody(&r.Body)
+	head, err := httputil.DumpResponse(r, false)
+	if err != nil {
+		return "", err
+	}
+	return s.debugFormatter().format(head, r.Header.Get("Content-Type"), body, true), nil
+}
+
+// A debugFormatter formats dumps according to DebugOptions.
+type debugFormatter struct {
+	DebugOptions
+	color bool
+}
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiCyan   = "\x1b[36m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+	ansiRed    = "\x1b[31m"
+	ansiGray   = "\x1b[90m"
+)
+
+// format returns the dumped head followed by the formatted body.
+func (f *debugFormatter) format(head []byte, contentType string, body []byte, isResponse bool) string {
+	var out strings.Builder
+	lines := strings.Split(strings.TrimRight(string(head), "\r\n"), "\r\n")
+	for i, line := range lines {
+		switch {
+		case !f.color:
+			out.WriteString(line)
+		case i == 0 && isResponse:
+			out.WriteString(ansiBold + statusColor(line) + line + ansiReset)
+		case i == 0:
+			out.WriteString(ansiBold + line + ansiReset)
+		default:
+			if j := strings.IndexByte(line, ':'); j >= 0 {
+				out.WriteString(ansiCyan + line[:j] + ansiReset + line[j:])
+			} else {
+				out.WriteString(line)
+			}
+		}
+		out.WriteString("\r\n")
+	}
+	out.WriteString("\r\n")
+	if len(body) != 0 {
+		formatted := f.formatBody(contentType, body)
+		if f.color && strings.HasPrefix(formatted, "<") && !isText(contentType, body) {
+			formatted = ansiGray + formatted + ansiReset
+		}
+		out.WriteString(formatted)
+	}
+	return out.String()
+}
+
+// statusColor returns the color of the status line of a response.
+func statusColor(statusLine string) string {
+	fields := strings.Fields(statusLine)
+	if len(fields) < 2 || fields[1] == "" {
+		return ""
+	}
+	switch fields[1][0] {
+	case '2':
+		return ansiGreen
+	case '3':
+		return ansiYellow
+	case '4', '5':
+		return ansiRed
+	}
+	return ""
+}
+
+// formatBody pretty-prints, summarizes and truncates body.
+func (f *debugFormatter) formatBody(contentType string, body []byte) string {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	if strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "" {
+		if summary, ok := f.formatMultipart(body, params["boundary"]); ok {
+			return summary
+		}
+	}
+	if !isText(contentType, body) {
+		return fmt.Sprintf("<binary body: %s, %d bytes>", describeType(mediaType), len(body))
+	}
+	if f.Pretty {
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			var buf bytes.Buffer
+			if json.Indent(&buf, body, "", "  ") == nil {
+				body = buf.Bytes()
+			}
+		case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+			if pretty, err := indentXML(body); err == nil {
+				body = pretty
+			}
+		}
+	}
+	return f.truncate(body)
+}
+
+// formatMultipart summarizes the parts of a multipart body, showing the value of text fields.
+func (f *debugFormatter) formatMultipart(body []byte, boundary string) (string, bool) {
+	var out strings.Builder
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+		data, err := ioutil.ReadAll(part)
+		if err != nil {
+			return "", false
+		}
+		partType := part.Header.Get("Content-Type")
+		if part.FileName() == "" && isText(partType, data) {
+			fmt.Fprintf(&out, "<part %q: %s>\r\n", part.FormName(), f.truncate(data))
+		} else {
+			fmt.Fprintf(&out, "<part %q, file %q: %s, %d bytes>\r\n", part.FormName(), part.FileName(), describeType(partType), len(data))
+		}
+	}
+	return out.String(), true
+}
+
+// truncate cuts body at MaxBodySize, on a character boundary.
+func (f *debugFormatter) truncate(body []byte) string {
+	if f.MaxBodySize <= 0 || len(body) <= f.MaxBodySize {
+		return string(body)
+	}
+	cut := f.MaxBodySize
+	for cut > 0 && !utf8.RuneStart(body[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s... <truncated, %d bytes total>", body[:cut], len(body))
+}
+
+// isText tells whether a body can be printed.
+func isText(contentType string, body []byte) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "", strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "json"), strings.HasSuffix(mediaType, "xml"),
+		mediaType == "application/x-www-form-urlencoded", mediaType == "application/javascript":
+		return utf8.Valid(body)
+	}
+	return false
+}
+
+func describeType(mediaType string) string {
+	if mediaType == "" {
+		return "application/octet-stream"
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err == nil {
+		return parsed
+	}
+	return mediaType
+}
+
+// indentXML re-encodes an XML document with indentation. Documents with namespaces are
+// refused, as encoding/xml doesn't write them back as they were.
+func indentXML(body []byte) ([]byte, error) {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	var buf bytes.Buffer
+	encoder := xml.NewEncoder(&buf)
+	encoder.Indent("", "  ")
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) == 0 {
+				continue
+			}
+		case xml.StartElement:
+			if t.Name.Space != "" {
+				return nil, errors.New("xml namespaces")
+			}
+			for _, attr := range t.Attr {
+				if attr.Name.Space != "" || attr.Name.Local == "xmlns" {
+					return nil, errors.New("xml namespaces")
+				}
+			}
+		}
+		if err := encoder.EncodeToken(xml.CopyToken(token)); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isTerminal tells whether logger is a *log.Logger writing to a terminal.
+func isTerminal(logger Logger) bool {
+	l, ok := logger.(*log.Logger)
+	if !ok {
+		return false
+	}
+	file, ok := l.Writer().(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,75 @@
+package gorequest
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFormatBody(t *testing.T) {
+	pretty := &debugFormatter{DebugOptions: DebugOptions{Pretty: true, MaxBodySize: 40}}
+	plain := &debugFormatter{DebugOptions: DebugOptions{MaxBodySize: 5}}
+	cases := []struct {
+		formatter   *debugFormatter
+		contentType string
+		body        string
+		expected    string
+	}{
+		{pretty, "application/json", `{"a":[1,2]}`, "{\n  \"a\": [\n    1,\n    2\n  ]\n}"},
+		{pretty, "application/xml", `<a><b x="1">t</b></a>`, "<a>\n  <b x=\"1\">t</b>\n</a>"},
+		// namespaces are kept as they are
+		{pretty, "application/xml", `<a xmlns="urn:x"><b/></a>`, `<a xmlns="urn:x"><b/></a>`},
+		{pretty, "application/json", `{"a":"` + strings.Repeat("x", 40) + `"}`, "{\n  \"a\": \"" + strings.Repeat("x", 30) + "... <truncated, 53 bytes total>"},
+		{plain, "text/plain", "hhhhé", "hhhh... <truncated, 6 bytes total>"},
+		{plain, "image/png", "\x89PNG\r\n", "<binary body: image/png, 6 bytes>"},
+		{plain, "", "\xff\xfe", "<binary body: application/octet-stream, 2 bytes>"},
+		// bodies are not truncated by default
+		{&debugFormatter{DebugOptions: DefaultDebugOptions}, "text/plain", strings.Repeat("x", 100<<10), strings.Repeat("x", 100<<10)},
+	}
+	for _, c := range cases {
+		if actual := c.formatter.formatBody(c.contentType, []byte(c.body)); actual != c.expected {
+			t.Error(fmt.Sprintf("Expected %q | but got %q", c.expected, actual))
+		}
+	}
+}
+
+func TestDebugOptions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":1}`))
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	New().SetLogger(log.New(&buf, "", 0)).SetDebug(true).
+		SetDebugOptions(DebugOptions{Pretty: true, Color: ColorAlways}).
+		Post(ts.URL).
+		Type("multipart").
+		Send(`{"name":"gopher"}`).
+		SendFile(bytes.Repeat([]byte{0}, 1000), "zeros.bin", "upload").
+		End()
+	out := buf.String()
+	for _, expected := range []string{
+		`<part "name": gopher>`,
+		`<part "upload", file "zeros.bin": application/octet-stream, 1000 bytes>`,
+		ansiBold + ansiGreen + "HTTP/1.1 201 Created" + ansiReset,
+		ansiCyan + "Content-Type" + ansiReset + ": application/json",
+		"{\n  \"id\": 1\n}",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Error(fmt.Sprintf("Expected %q in debug output | but got %q", expected, out))
+		}
+	}
+
+	// ColorAuto doesn't colorize a buffer
+	buf.Reset()
+	New().SetLogger(log.New(&buf, "", 0)).SetDebug(true).SetDebugOptions(DebugOptions{Color: ColorAuto}).Get(ts.URL).End()
+	if strings.Contains(buf.String(), "\x1b[") || !strings.Contains(buf.String(), `{"id":1}`) {
+		t.Error(fmt.Sprintf("Expected plain output | but got %q", buf.String()))
+	}
+}
@@ -0,0 +1,234 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"moul.io/http2curl/v2"
+)
+
+// An Exporter describes a request in another tool's syntax, see SuperAgent.Export.
+type Exporter interface {
+	// Export returns the description of req, whose body is body.
+	Export(req *http.Request, body []byte) (string, error)
+}
+
+// ExporterFunc adapts a function to the Exporter interface.
+type ExporterFunc func(req *http.Request, body []byte) (string, error)
+
+func (f ExporterFunc) Export(req *http.Request, body []byte) (string, error) {
+	return f(req, body)
+}
+
+// The built-in exporters.
+var (
+	// CurlExporter exports a curl command, as AsCurlCommand.
+	CurlExporter Exporter = ExporterFunc(exportCurl)
+	// HTTPieExporter exports an HTTPie command.
+	HTTPieExporter Exporter = ExporterFunc(exportHTTPie)
+	// WgetExporter exports a wget command.
+	WgetExporter Exporter = ExporterFunc(exportWget)
+	// PowerShellExporter exports an Invoke-WebRequest PowerShell command.
+	PowerShellExporter Exporter = ExporterFunc(exportPowerShell)
+	// FetchExporter exports a JavaScript fetch call.
+	FetchExporter Exporter = ExporterFunc(exportFetch)
+)
+
+// Exporters are the exporters by name. Custom exporters can be added to it.
+var Exporters = map[string]Exporter{
+	"curl":       CurlExporter,
+	"httpie":     HTTPieExporter,
+	"wget":       WgetExporter,
+	"powershell": PowerShellExporter,
+	"fetch":      FetchExporter,
+}
+
+// Export returns the request End would send, made by MakeRequest, in the syntax of
+// exporter, secrets redacted, see SetRedaction:
+//
+//	cmd, err := gorequest.New().
+//	  Post("https://api.example.com/users").
+//	  Send(`{"name":"gopher"}`).
+//	  Export(gorequest.HTTPieExporter)
+//	// http --ignore-stdin POST https://api.example.com/users Content-Type:application/json --raw '{"name":"gopher"}'
+func (s *SuperAgent) Export(exporter Exporter) (string, error) {
+	if len(s.Errors) != 0 {
+		return "", s.Errors[0]
+	}
+	s.resolveTargetType()
+	req, err := s.MakeRequest()
+	if err != nil {
+		return "", err
+	}
+	r, err := s.redaction().redactRequest(req)
+	if err != nil {
+		return "", err
+	}
+	body, err := readBody(&r.Body)
+	if err != nil {
+		return "", err
+	}
+	return exporter.Export(r, body)
+}
+
+func exportCurl(req *http.Request, body []byte) (string, error) {
+	if body != nil {
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	cmd, err := http2curl.GetCurlCommand(req)
+	if err != nil {
+		return "", err
+	}
+	return cmd.String(), nil
+}
+
+func exportHTTPie(req *http.Request, body []byte) (string, error) {
+	args := []string{"http", "--ignore-stdin", req.Method, shellQuote(req.URL.String())}
+	for _, name := range exportedHeaders(req) {
+		for _, value := range req.Header.Values(name) {
+			if value == "" {
+				args = append(args, shellQuote(name+";"))
+			} else {
+				args = append(args, shellQuote(name+":"+value))
+			}
+		}
+	}
+	if len(body) != 0 {
+		args = append(args, "--raw", shellQuote(string(body)))
+	}
+	return strings.Join(args, " "), nil
+}
+
+func exportWget(req *http.Request, body []byte) (string, error) {
+	args := []string{"wget", "-q", "-O", "-", "--method=" + req.Method}
+	for _, name := range exportedHeaders(req) {
+		for _, value := range req.Header.Values(name) {
+			args = append(args, "--header="+shellQuote(name+": "+value))
+		}
+	}
+	if len(body) != 0 {
+		args = append(args, "--body-data="+shellQuote(string(body)))
+	}
+	args = append(args, shellQuote(req.URL.String()))
+	return strings.Join(args, " "), nil
+}
+
+func exportPowerShell(req *http.Request, body []byte) (string, error) {
+	args := []string{"Invoke-WebRequest", "-Uri", powerShellQuote(req.URL.String()), "-Method", req.Method}
+	var headers []string
+	for _, name := range exportedHeaders(req) {
+		value := strings.Join(req.Header.Values(name), ", ")
+		// Windows PowerShell refuses these headers in -Headers
+		switch name {
+		case "Content-Type":
+			args = append(args, "-ContentType", powerShellQuote(value))
+		case "User-Agent":
+			args = append(args, "-UserAgent", powerShellQuote(value))
+		default:
+			headers = append(headers, powerShellQuote(name)+" = "+powerShellQuote(value))
+		}
+	}
+	if len(headers) != 0 {
+		args = append(args, "-Headers", "@{ "+strings.Join(headers, "; ")+" }")
+	}
+	if len(body) != 0 {
+		args = append(args, "-Body", powerShellQuote(string(body)))
+	}
+	return strings.Join(args, " "), nil
+}
+
+func exportFetch(req *http.Request, body []byte) (string, error) {
+	var out strings.Builder
+	fmt.Fprintf(&out, "fetch(%s, {\n  method: %s", jsString(req.URL.String()), jsString(req.Method))
+	if names := exportedHeaders(req); len(names) != 0 {
+		out.WriteString(",\n  headers: {")
+		for i, name := range names {
+			if i > 0 {
+				out.WriteString(",")
+			}
+			fmt.Fprintf(&out, "\n    %s: %s", jsString(name), jsString(strings.Join(req.Header.Values(name), ", ")))
+		}
+		out.WriteString("\n  }")
+	}
+	if len(body) != 0 {
+		fmt.Fprintf(&out, ",\n  body: %s", jsString(string(body)))
+	}
+	out.WriteString("\n});")
+	return out.String(), nil
+}
+
+// exportedHeaders returns the sorted names of the headers of req.
+func exportedHeaders(req *http.Request) []string {
+	names := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// shellQuote quotes s for POSIX shells, with $'...' if it contains control characters.
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:=@%+,", r)))
+	}) < 0 {
+		return s
+	}
+	if strings.IndexFunc(s, func(r rune) bool { return r != '\n' && unicode.IsControl(r) }) < 0 && utf8.ValidString(s) {
+		return "'" + strings.Replace(s, "'", `'\''`, -1) + "'"
+	}
+	var out strings.Builder
+	out.WriteString("$'")
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' || c == '\'':
+			out.WriteByte('\\')
+			out.WriteByte(c)
+		case c == '\n':
+			out.WriteString(`\n`)
+		case c == '\r':
+			out.WriteString(`\r`)
+		case c == '\t':
+			out.WriteString(`\t`)
+		case c < 0x20 || c == 0x7f:
+			fmt.Fprintf(&out, `\x%02x`, c)
+		case c >= utf8.RuneSelf:
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				fmt.Fprintf(&out, `\x%02x`, c)
+			} else {
+				out.WriteString(s[i : i+size])
+				i += size - 1
+			}
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteString("'")
+	return out.String()
+}
+
+// powerShellQuotes are the single quotes of PowerShell, doubled in verbatim strings.
+var powerShellQuotes = strings.NewReplacer("'", "''", "\u2018", "\u2018\u2018", "\u2019", "\u2019\u2019",
+	"\u201a", "\u201a\u201a", "\u201b", "\u201b\u201b")
+
+// powerShellQuote quotes s as a PowerShell verbatim string.
+func powerShellQuote(s string) string {
+	return "'" + powerShellQuotes.Replace(s) + "'"
+}
+
+// jsString quotes s as a JavaScript string.
+func jsString(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,71 @@
+package gorequest
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	request := func() *SuperAgent {
+		return New().
+			Post("https://api.example.com/users?page=1").
+			Set("X-Api-Key", "secret").
+			Set("X-Request-Id", "it's").
+			Send(`{"name":"gopher","password":"hunter2"}`)
+	}
+	expected := map[string]string{
+		"httpie": `http --ignore-stdin POST 'https://api.example.com/users?page=1' Content-Type:application/json ` +
+			`'X-Api-Key:[REDACTED]' 'X-Request-Id:it'\''s' --raw '{"name":"gopher","password":"[REDACTED]"}'`,
+		"wget": `wget -q -O - --method=POST --header='Content-Type: application/json' --header='X-Api-Key: [REDACTED]' ` +
+			`--header='X-Request-Id: it'\''s' --body-data='{"name":"gopher","password":"[REDACTED]"}' 'https://api.example.com/users?page=1'`,
+		"powershell": `Invoke-WebRequest -Uri 'https://api.example.com/users?page=1' -Method POST -ContentType 'application/json' ` +
+			`-Headers @{ 'X-Api-Key' = '[REDACTED]'; 'X-Request-Id' = 'it''s' } -Body '{"name":"gopher","password":"[REDACTED]"}'`,
+		"fetch": `fetch("https://api.example.com/users?page=1", {
+  method: "POST",
+  headers: {
+    "Content-Type": "application/json",
+    "X-Api-Key": "[REDACTED]",
+    "X-Request-Id": "it's"
+  },
+  body: "{\"name\":\"gopher\",\"password\":\"[REDACTED]\"}"
+});`,
+	}
+	for name, want := range expected {
+		got, err := request().Export(Exporters[name])
+		if err != nil || got != want {
+			t.Error(fmt.Sprintf("Expected %s export %s | but got %s, %v", name, want, got, err))
+		}
+	}
+
+	curl, _ := request().Export(CurlExporter)
+	asCurl, _ := request().AsCurlCommand()
+	if curl != asCurl {
+		t.Error(fmt.Sprintf("Expected the curl export to equal AsCurlCommand %s | but got %s", asCurl, curl))
+	}
+
+	custom := ExporterFunc(func(req *http.Request, body []byte) (string, error) {
+		return req.Method + " " + req.URL.Path + " " + string(body), nil
+	})
+	got, _ := New().Put("http://example.com/a").Type("text").Send("b").Export(custom)
+	if got != "PUT /a b" {
+		t.Error(fmt.Sprintf("Expected the custom export | but got %s", got))
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	words := []string{"plain", "", "a b", "it's", "line\nbreak", "tab\tand\rcr", "\x00\xff binary", "é", "back\\slash"}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = shellQuote(word)
+	}
+	split, err := splitShellWords(strings.Join(quoted, " "))
+	if err != nil || !reflect.DeepEqual(split, words) {
+		t.Error(fmt.Sprintf("Expected %q | but got %q, %v", words, split, err))
+	}
+	if quoted[0] != "plain" || quoted[5] != `$'tab\tand\rcr'` {
+		t.Error(fmt.Sprintf("Expected minimal quoting | but got %q", quoted))
+	}
+}
@@ -0,0 +1,269 @@
+package gorequest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// A Fault describes what goes wrong with a request, see FaultInjector.
+// The zero Fault lets the request through.
+type Fault struct {
+	// Latency delays the request.
+	Latency time.Duration
+	// Reset fails the request with a connection reset, without sending it.
+	Reset bool
+	// Status answers the request with an empty response of this status, without sending it.
+	// TruncateBody and DripInterval apply to this response too.
+	Status int
+	// TruncateBody fails the response body with io.ErrUnexpectedEOF after TruncateAfter bytes.
+	TruncateBody  bool
+	TruncateAfter int
+	// DripInterval slows down the response body: DripBytes bytes, 1 by default, are
+	// returned every DripInterval.
+	DripInterval time.Duration
+	DripBytes    int
+}
+
+func (f Fault) String() string {
+	switch {
+	case f.Reset:
+		return "reset"
+	case f.Status != 0:
+		return fmt.Sprintf("status %d", f.Status)
+	case f.TruncateBody:
+		return fmt.Sprintf("truncate after %d bytes", f.TruncateAfter)
+	case f.DripInterval > 0:
+		return fmt.Sprintf("drip every %v", f.DripInterval)
+	case f.Latency > 0:
+		return fmt.Sprintf("latency %v", f.Latency)
+	}
+	return "none"
+}
+
+type faultRule struct {
+	probability float64
+	fault       Fault
+}
+
+// A FaultInjector injects faults in requests, to test how code using gorequest, and its
+// Retry, cope with failures. Faults are either scripted, applied in order to the next
+// requests, or drawn with a probability from a random generator seeded for failures to
+// be reproducible:
+//
+//	faults := gorequest.NewFaultInjector(42).
+//	  Script(gorequest.Fault{Status: 503}, gorequest.Fault{Reset: true}).
+//	  With(0.1, gorequest.Fault{Latency: 2 * time.Second}).
+//	  With(0.05, gorequest.Fault{TruncateBody: true, TruncateAfter: 100})
+//	gorequest.New().InjectFaults(faults).Retry(3, time.Second, 503).Get(url).End()
+//
+// It is safe for concurrent use, though the faults drawn by concurrent requests then
+// depend on their order.
+type FaultInjector struct {
+	mu     sync.Mutex
+	rng    *rand.Rand
+	script []Fault
+	rules  []faultRule
+	// injected holds the last InjectedHistory faults, from oldest, oldest wrapping around.
+	injected []Fault
+	oldest   int
+	counts   map[string]int
+}
+
+// InjectedHistory is the number of faults kept for Injected, the older ones being
+// only counted by Counts, so that long-running injectors use bounded memory.
+const InjectedHistory = 1000
+
+// NewFaultInjector returns a FaultInjector drawing faults with a generator seeded with seed.
+func NewFaultInjector(seed int64) *FaultInjector {
+	return &FaultInjector{rng: rand.New(rand.NewSource(seed)), counts: map[string]int{}}
+}
+
+// Script appends faults to the ones applied in order to the next requests, before
+// drawing faults with With.
+func (f *FaultInjector) Script(faults ...Fault) *FaultInjector {
+	f.mu.Lock()
+	f.script = append(f.script, faults...)
+	f.mu.Unlock()
+	return f
+}
+
+// With injects fault in requests with probability, between 0 and 1. The probabilities
+// of several faults add up, as at most one is injected per request.
+func (f *FaultInjector) With(probability float64, fault Fault) *FaultInjector {
+	f.mu.Lock()
+	f.rules = append(f.rules, faultRule{probability: probability, fault: fault})
+	f.mu.Unlock()
+	return f
+}
+
+// Injected returns the last InjectedHistory faults injected, oldest first, the zero Fault
+// standing for requests let through.
+func (f *FaultInjector) Injected() []Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append(append([]Fault(nil), f.injected[f.oldest:]...), f.injected[:f.oldest]...)
+}
+
+// Counts returns the number of faults injected so far by kind, see Fault.String, "none"
+// counting the requests let through.
+func (f *FaultInjector) Counts() map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	counts := make(map[string]int, len(f.counts))
+	for kind, n := range f.counts {
+		counts[kind] = n
+	}
+	return counts
+}
+
+// next returns the fault of the next request.
+func (f *FaultInjector) next() Fault {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var fault Fault
+	if len(f.script) != 0 {
+		fault, f.script = f.script[0], f.script[1:]
+	} else if len(f.rules) != 0 {
+		draw, cumulated := f.rng.Float64(), 0.0
+		for _, rule := range f.rules {
+			cumulated += rule.probability
+			if draw < cumulated {
+				fault = rule.fault
+				break
+			}
+		}
+	}
+	if len(f.injected) < InjectedHistory {
+		f.injected = append(f.injected, fault)
+	} else {
+		f.injected[f.oldest] = fault
+		f.oldest = (f.oldest + 1) % InjectedHistory
+	}
+	f.counts[fault.String()]++
+	return fault
+}
+
+// RoundTripper returns base with faults injected, for use outside of a SuperAgent.
+func (f *FaultInjector) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	return &faultTransport{base: base, injector: f}
+}
+
+// InjectFaults makes the SuperAgent inject the faults of injector in its requests.
+func (s *SuperAgent) InjectFaults(injector *FaultInjector) *SuperAgent {
+	s.faultInjector = injector
+	return s
+}
+
+// InjectFaults returns a Client injecting faults in its requests, see SuperAgent.InjectFaults.
+func (c *Client) InjectFaults(injector *FaultInjector) *Client {
+	return c.with(func(s *SuperAgent) { s.InjectFaults(injector) })
+}
+
+type faultTransport struct {
+	base     http.RoundTripper
+	injector *FaultInjector
+}
+
+func (t *faultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fault := t.injector.next()
+	if fault.Latency > 0 {
+		timer := time.NewTimer(fault.Latency)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			closeRequestBody(req)
+			return nil, req.Context().Err()
+		}
+	}
+	if fault.Reset {
+		closeRequestBody(req)
+		return nil, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+	}
+	var resp *http.Response
+	if fault.Status != 0 {
+		closeRequestBody(req)
+		resp = &http.Response{
+			Status:     fmt.Sprintf("%d %s", fault.Status, http.StatusText(fault.Status)),
+			StatusCode: fault.Status,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{},
+			Body:       ioutil.NopCloser(bytes.NewReader(nil)),
+			Request:    req,
+		}
+	} else {
+		var err error
+		if resp, err = t.base.RoundTrip(req); err != nil {
+			return nil, err
+		}
+	}
+	if fault.TruncateBody {
+		resp.Body = &truncatedBody{ReadCloser: resp.Body, remaining: fault.TruncateAfter}
+	}
+	if fault.DripInterval > 0 {
+		chunk := fault.DripBytes
+		if chunk <= 0 {
+			chunk = 1
+		}
+		resp.Body = &dripBody{ReadCloser: resp.Body, ctx: req.Context(), interval: fault.DripInterval, chunk: chunk}
+	}
+	return resp, nil
+}
+
+// closeRequestBody closes the body of a request which won't be sent, as a RoundTripper must.
+func closeRequestBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
+
+// A truncatedBody fails with io.ErrUnexpectedEOF once remaining bytes were read.
+type truncatedBody struct {
+	io.ReadCloser
+	remaining int
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= n
+	return n, err
+}
+
+// A dripBody returns chunk bytes every interval, until the context of the request is done.
+type dripBody struct {
+	io.ReadCloser
+	ctx      context.Context
+	interval time.Duration
+	chunk    int
+}
+
+func (b *dripBody) Read(p []byte) (int, error) {
+	timer := time.NewTimer(b.interval)
+	select {
+	case <-timer.C:
+	case <-b.ctx.Done():
+		timer.Stop()
+		return 0, b.ctx.Err()
+	}
+	if len(p) > b.chunk {
+		p = p[:b.chunk]
+	}
+	return b.ReadCloser.Read(p)
+}
@@ -0,0 +1,121 @@
+package gorequest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+func TestFaultInjectorScript(t *testing.T) {
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Write([]byte("hello"))
+	}))
+	defer ts.Close()
+
+	faults := NewFaultInjector(1).Script(Fault{Status: http.StatusServiceUnavailable}, Fault{Status: http.StatusBadGateway})
+	resp, body, errs := New().InjectFaults(faults).
+		Retry(2, time.Millisecond, http.StatusServiceUnavailable, http.StatusBadGateway).
+		Get(ts.URL).End()
+	if errs != nil || resp.StatusCode != http.StatusOK || body != "hello" || calls != 1 {
+		t.Error(fmt.Sprintf("Expected the retry to succeed after 2 faults | but got %v, %q, %v, %d calls", resp, body, errs, calls))
+	}
+	if resp.Header.Get("Retry-Count") != "2" {
+		t.Error(fmt.Sprintf("Expected Retry-Count 2 | but got %q", resp.Header.Get("Retry-Count")))
+	}
+
+	faults.Script(Fault{Reset: true})
+	_, _, errs = New().InjectFaults(faults).Get(ts.URL).End()
+	if len(errs) != 1 || !errors.Is(errs[0], syscall.ECONNRESET) {
+		t.Error(fmt.Sprintf("Expected a connection reset | but got %v", errs))
+	}
+
+	faults.Script(Fault{TruncateBody: true, TruncateAfter: 2})
+	_, _, errs = New().InjectFaults(faults).Get(ts.URL).End()
+	if len(errs) != 1 || !errors.Is(errs[0], io.ErrUnexpectedEOF) {
+		t.Error(fmt.Sprintf("Expected a truncated body | but got %v", errs))
+	}
+
+	// the body of a status fault is truncated too
+	calls = 0
+	faults.Script(Fault{Status: http.StatusBadGateway, TruncateBody: true})
+	_, _, errs = New().InjectFaults(faults).Get(ts.URL).End()
+	if len(errs) != 1 || !errors.Is(errs[0], io.ErrUnexpectedEOF) || calls != 0 {
+		t.Error(fmt.Sprintf("Expected a truncated status response | but got %v, %d calls", errs, calls))
+	}
+
+	faults.Script(Fault{Latency: 20 * time.Millisecond, DripInterval: time.Millisecond, DripBytes: 2})
+	start := time.Now()
+	_, body, errs = New().InjectFaults(faults).Get(ts.URL).End()
+	if errs != nil || body != "hello" || time.Since(start) < 20*time.Millisecond {
+		t.Error(fmt.Sprintf("Expected a slow response | but got %q, %v after %v", body, errs, time.Since(start)))
+	}
+
+	// a drip ends with the request
+	faults.Script(Fault{DripInterval: time.Hour})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	_, _, errs = New().InjectFaults(faults).Get(ts.URL).Context(ctx).End()
+	if len(errs) != 1 || !errors.Is(errs[0], context.DeadlineExceeded) || time.Since(start) > time.Second {
+		t.Error(fmt.Sprintf("Expected the drip to stop with the request | but got %v after %v", errs, time.Since(start)))
+	}
+
+	faults.Script(Fault{Latency: time.Second})
+	_, _, errs = New().Timeout(10 * time.Millisecond).InjectFaults(faults).Get(ts.URL).End()
+	if errs == nil {
+		t.Error("Expected the injected latency to time out | but got no error")
+	}
+}
+
+func TestFaultInjectorSeed(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	run := func() []Fault {
+		faults := NewFaultInjector(42).
+			With(0.3, Fault{Status: http.StatusInternalServerError}).
+			With(0.2, Fault{Latency: time.Millisecond})
+		client := NewClient(ts.URL).InjectFaults(faults)
+		for i := 0; i < 20; i++ {
+			client.Get("/").End()
+		}
+		return faults.Injected()
+	}
+	first, second := run(), run()
+	if !reflect.DeepEqual(first, second) {
+		t.Error(fmt.Sprintf("Expected the same faults with the same seed | but got %v and %v", first, second))
+	}
+	counts := map[string]int{}
+	for _, fault := range first {
+		counts[fault.String()]++
+	}
+	if len(first) != 20 || counts["status 500"] == 0 || counts["latency 1ms"] == 0 || counts["none"] == 0 {
+		t.Error(fmt.Sprintf("Expected a mix of faults | but got %v", counts))
+	}
+}
+
+func TestFaultInjectorHistory(t *testing.T) {
+	faults := NewFaultInjector(1).With(0.5, Fault{Status: http.StatusInternalServerError})
+	for i := 0; i < InjectedHistory+10; i++ {
+		faults.next()
+	}
+	faults.Script(Fault{Reset: true})
+	faults.next()
+	injected, counts := faults.Injected(), faults.Counts()
+	if len(injected) != InjectedHistory || injected[len(injected)-1] != (Fault{Reset: true}) {
+		t.Error(fmt.Sprintf("Expected the last %d faults, ending with a reset | but got %d, %v", InjectedHistory, len(injected), injected[len(injected)-1]))
+	}
+	if counts["status 500"]+counts["none"] != InjectedHistory+10 || counts["reset"] != 1 {
+		t.Error(fmt.Sprintf("Expected every fault counted | but got %v", counts))
+	}
+}
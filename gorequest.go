@@ -10,10 +10,10 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"log/slog"
 	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
-	"net/http/httputil"
 	"net/textproto"
 	"net/url"
 	"os"
@@ -25,7 +25,6 @@ import (
 
 	"github.com/pkg/errors"
 	"golang.org/x/net/publicsuffix"
-	"moul.io/http2curl/v2"
 )
 
 type Request *http.Request
@@ -54,6 +53,14 @@ const (
 	TypeMultipart  = "multipart"
 )
 
+// Array formats we support when encoding slices into a query string or form body.
+const (
+	ArrayFormatRepeat   = "repeat"   // ids=1&ids=2
+	ArrayFormatBrackets = "brackets" // ids[]=1&ids[]=2
+	ArrayFormatComma    = "comma"    // ids=1,2
+	ArrayFormatPipe     = "pipe"     // ids=1|2
+)
+
 type superAgentRetryable struct {
 	RetryableStatus []int
 	RetryerTime     time.Duration
@@ -63,6 +70,8 @@ type superAgentRetryable struct {
 }
 
 // A SuperAgent is a object storing all request data for client.
+// A SuperAgent is not safe for concurrent use. To send requests from several goroutines,
+// start each of them from a Client or from a Clone of a SuperAgent.
 type SuperAgent struct {
 	Url                  string
 	Method               string
@@ -73,6 +82,7 @@ type SuperAgent struct {
 	SliceData            []interface{}
 	FormData             url.Values
 	QueryData            url.Values
+	defaultQuery         url.Values
 	FileData             []File
 	BounceToRawString    bool
 	RawString            string
@@ -86,6 +96,27 @@ type SuperAgent struct {
 	logger               Logger
 	Retryable            superAgentRetryable
 	DoNotClearSuperAgent bool
+	clientDefaults       *SuperAgent
+	ArrayFormat          string
+	requestArrayFormat   string
+	pathParams           map[string]interface{}
+	baseURL              string
+	SharedTransport      bool
+	ownRoundTripper      bool
+	shared               *sharedTransport
+	proxyURL             string
+	dialTimeout          time.Duration
+	bodyIdleTimeout      time.Duration
+	trace                bool
+	tracer               Tracer
+	metrics              Metrics
+	slogger              *slog.Logger
+	logFields            []interface{}
+	redactionPolicy      *RedactionPolicy
+	debugOptions         *DebugOptions
+	harRecorder          *HARRecorder
+	cassette             *Cassette
+	faultInjector        *FaultInjector
 	isClone              bool
 	context				 context.Context
 }
@@ -118,12 +149,15 @@ func New() *SuperAgent {
 		BasicAuth:         struct{ Username, Password string }{},
 		Debug:             debug,
 		CurlCommand:       false,
+		ArrayFormat:       ArrayFormatRepeat,
 		logger:            log.New(os.Stderr, "[gorequest]", log.LstdFlags),
 		isClone:           false,
 		context:           nil,
 	}
 	// disable keep alives by default, see this issue https://github.com/parnurzeal/gorequest/issues/75
 	s.Transport.DisableKeepAlives = true
+	// set it now so that clones sharing the client never have to swap it while sending
+	s.Client.Transport = s.Transport
 	return s
 }
 
@@ -203,9 +237,10 @@ func copyRetryable(old superAgentRetryable) superAgentRetryable {
 // Note: This does a shallow copy of the parent. So you will need to be
 // careful of Data provided
 // Note: It also directly re-uses the client and transport. If you modify the Timeout,
-// or RedirectPolicy on a clone, the clone will have a new http.client. It is recommended
+// or RedirectPolicy on a clone, the clone will have a new http.client. If you modify the
+// TLSClientConfig or Proxy on a clone, it will have a new http.client and transport. It is recommended
 // that the base request set your timeout and redirect polices, and no modification of
-// the client or transport happen after cloning.
+// the client or transport happen after cloning. See Client for a base request which can't be modified.
 // Note: DoNotClearSuperAgent is forced to "true" after Clone
 func (s *SuperAgent) Clone() *SuperAgent {
 	clone := &SuperAgent{
@@ -218,6 +253,7 @@ func (s *SuperAgent) Clone() *SuperAgent {
 		SliceData:            shallowCopyDataSlice(s.SliceData),
 		FormData:             url.Values(cloneMapArray(s.FormData)),
 		QueryData:            url.Values(cloneMapArray(s.QueryData)),
+		defaultQuery:         url.Values(cloneMapArray(s.defaultQuery)),
 		FileData:             shallowCopyFileArray(s.FileData),
 		BounceToRawString:    s.BounceToRawString,
 		RawString:            s.RawString,
@@ -231,6 +267,27 @@ func (s *SuperAgent) Clone() *SuperAgent {
 		logger:               s.logger, // thread safe.. anyway
 		Retryable:            copyRetryable(s.Retryable),
 		DoNotClearSuperAgent: true,
+		clientDefaults:       s.clientDefaults,
+		ArrayFormat:          s.ArrayFormat,
+		requestArrayFormat:   s.requestArrayFormat,
+		pathParams:           shallowCopyData(s.pathParams),
+		baseURL:              s.baseURL,
+		SharedTransport:      s.SharedTransport,
+		shared:               s.shared,
+		ownRoundTripper:      s.ownRoundTripper,
+		proxyURL:             s.proxyURL,
+		dialTimeout:          s.dialTimeout,
+		bodyIdleTimeout:      s.bodyIdleTimeout,
+		trace:                s.trace,
+		tracer:               s.tracer,
+		metrics:              s.metrics,
+		slogger:              s.slogger,
+		logFields:            append([]interface{}(nil), s.logFields...),
+		redactionPolicy:      s.redactionPolicy,
+		debugOptions:         s.debugOptions,
+		harRecorder:          s.harRecorder,
+		cassette:             s.cassette,
+		faultInjector:        s.faultInjector,
 		isClone:              true,
 		context: 			  s.context,
 	}
@@ -260,6 +317,73 @@ func (s *SuperAgent) SetLogger(logger Logger) *SuperAgent {
 	return s
 }
 
+var arrayFormats = map[string]bool{
+	ArrayFormatRepeat:   true,
+	ArrayFormatBrackets: true,
+	ArrayFormatComma:    true,
+	ArrayFormatPipe:     true,
+}
+
+// SetArrayFormat sets how slices are encoded by Query and by form or multipart bodies
+// for every request made with this SuperAgent. The default is "repeat".
+//
+//    gorequest.New().
+//      SetArrayFormat(gorequest.ArrayFormatBrackets).
+//      Get("/users").
+//      Query(map[string]interface{}{"ids": []int{1, 2}}).
+//      End()
+//
+// This will GET /users?ids[]=1&ids[]=2
+//
+// GoRequest supports
+//
+//    "repeat" makes ids=1&ids=2
+//    "brackets" makes ids[]=1&ids[]=2
+//    "comma" makes ids=1,2
+//    "pipe" makes ids=1|2
+//
+// Query encodes slices when it is called, with the format set then, so SetArrayFormat
+// has to come before Query: the values added by earlier Query calls keep their format.
+//
+func (s *SuperAgent) SetArrayFormat(format string) *SuperAgent {
+	if !arrayFormats[format] {
+		s.Errors = append(s.Errors, errors.New("SetArrayFormat func: incorrect array format \""+format+"\""))
+		return s
+	}
+	s.ArrayFormat = format
+	return s
+}
+
+// UseArrayFormat works like SetArrayFormat but only for the current request.
+// It is reset by the next Get, Post, etc. unless DoNotClearSuperAgent is set.
+// Query encodes slices when it is called, so UseArrayFormat has to come before it, or
+// it won't apply to the values of earlier Query calls:
+//
+//    gorequest.New().
+//      Get("/users").
+//      UseArrayFormat(gorequest.ArrayFormatComma).
+//      Query(map[string]interface{}{"ids": []int{1, 2}}).
+//      End()
+//
+func (s *SuperAgent) UseArrayFormat(format string) *SuperAgent {
+	if !arrayFormats[format] {
+		s.Errors = append(s.Errors, errors.New("UseArrayFormat func: incorrect array format \""+format+"\""))
+		return s
+	}
+	s.requestArrayFormat = format
+	return s
+}
+
+func (s *SuperAgent) arrayFormat() string {
+	if s.requestArrayFormat != "" {
+		return s.requestArrayFormat
+	}
+	if s.ArrayFormat != "" {
+		return s.ArrayFormat
+	}
+	return ArrayFormatRepeat
+}
+
 // Clear SuperAgent data for another new request.
 func (s *SuperAgent) ClearSuperAgent() {
 	if s.DoNotClearSuperAgent {
@@ -278,8 +402,27 @@ func (s *SuperAgent) ClearSuperAgent() {
 	s.ForceType = ""
 	s.TargetType = TypeJSON
 	s.Cookies = make([]*http.Cookie, 0)
-	s.Errors = nil
+	s.clearErrors()
+	s.requestArrayFormat = ""
+	s.pathParams = nil
+	s.logFields = nil
 	s.context = nil
+	// a request started from a Client starts over from the Client defaults
+	if d := s.clientDefaults; d != nil {
+		s.Header = http.Header(cloneMapArray(d.Header))
+		s.defaultQuery = url.Values(cloneMapArray(d.QueryData))
+		s.BasicAuth = d.BasicAuth
+		s.Cookies = shallowCopyCookies(d.Cookies)
+	}
+}
+
+// clearErrors forgets the errors of the previous request, but keeps the ones of the
+// Client the request was started from.
+func (s *SuperAgent) clearErrors() {
+	s.Errors = nil
+	if d := s.clientDefaults; d != nil {
+		s.Errors = shallowCopyErrors(d.Errors)
+	}
 }
 
 // Just a wrapper to initialize SuperAgent instance by method string
@@ -303,7 +446,7 @@ func (s *SuperAgent) CustomMethod(method, targetUrl string) *SuperAgent {
 		s.ClearSuperAgent()
 		s.Method = method
 		s.Url = targetUrl
-		s.Errors = nil
+		s.clearErrors()
 		return s
 	}
 }
@@ -312,7 +455,7 @@ func (s *SuperAgent) Get(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = GET
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -320,7 +463,7 @@ func (s *SuperAgent) Post(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = POST
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -328,7 +471,7 @@ func (s *SuperAgent) Head(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = HEAD
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -336,7 +479,7 @@ func (s *SuperAgent) Put(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = PUT
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -344,7 +487,7 @@ func (s *SuperAgent) Delete(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = DELETE
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -352,7 +495,7 @@ func (s *SuperAgent) Patch(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = PATCH
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -360,7 +503,7 @@ func (s *SuperAgent) Options(targetUrl string) *SuperAgent {
 	s.ClearSuperAgent()
 	s.Method = OPTIONS
 	s.Url = targetUrl
-	s.Errors = nil
+	s.clearErrors()
 	return s
 }
 
@@ -519,12 +662,31 @@ func (s *SuperAgent) Type(typeStr string) *SuperAgent {
 //        Query(`{ size: '50x50', weight:'20kg' }`).
 //        End()
 //
+// Structs use their json names, unless they have `url` tags (see BindStruct for the tag format):
+//
+//      type Search struct {
+//        Query string `url:"query"`
+//        Size  string `url:"size,omitempty"`
+//      }
+//      gorequest.New().
+//        Get("/search").
+//        Query(Search{Query: "bicycle"}).
+//        End()
+//
 func (s *SuperAgent) Query(content interface{}) *SuperAgent {
 	switch v := reflect.ValueOf(content); v.Kind() {
 	case reflect.String:
 		s.queryString(v.String())
 	case reflect.Struct:
-		s.queryStruct(v.Interface())
+		if hasStructTag(v, TagURL) {
+			s.queryTagged(v.Interface())
+		} else {
+			s.queryStruct(v.Interface())
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			s.Query(v.Elem().Interface())
+		}
 	case reflect.Map:
 		s.queryMap(v.Interface())
 	default:
@@ -549,6 +711,16 @@ func (s *SuperAgent) queryStruct(content interface{}) *SuperAgent {
 					queryVal = strconv.FormatFloat(t, 'f', -1, 64)
 				case time.Time:
 					queryVal = t.Format(time.RFC3339)
+				case []interface{}:
+					if elements, ok := formatScalarSlice(reflect.ValueOf(t)); ok {
+						addArrayValues(s.QueryData, k, elements, s.arrayFormat())
+						continue
+					}
+					j, err := json.Marshal(v)
+					if err != nil {
+						continue
+					}
+					queryVal = string(j)
 				default:
 					j, err := json.Marshal(v)
 					if err != nil {
@@ -613,28 +785,13 @@ func (s *SuperAgent) safeModifyTransport() {
 	if !s.isClone {
 		return
 	}
-	oldTransport := s.Transport
-	s.Transport = &http.Transport{}
-
-	s.Transport.Proxy = oldTransport.Proxy
-	s.Transport.DialContext = oldTransport.DialContext
-	s.Transport.Dial = oldTransport.Dial
-	s.Transport.DialTLS = oldTransport.DialTLS
-	s.Transport.TLSClientConfig = oldTransport.TLSClientConfig
-	s.Transport.TLSHandshakeTimeout = oldTransport.TLSHandshakeTimeout
-	s.Transport.DisableKeepAlives = oldTransport.DisableKeepAlives
-	s.Transport.DisableCompression = oldTransport.DisableCompression
-	s.Transport.MaxIdleConns = oldTransport.MaxIdleConns
-	s.Transport.MaxIdleConnsPerHost = oldTransport.MaxIdleConnsPerHost
-	s.Transport.IdleConnTimeout = oldTransport.IdleConnTimeout
-	s.Transport.ResponseHeaderTimeout = oldTransport.ResponseHeaderTimeout
-	s.Transport.ExpectContinueTimeout = oldTransport.ExpectContinueTimeout
-	s.Transport.TLSNextProto = oldTransport.TLSNextProto
-	s.Transport.MaxResponseHeaderBytes = oldTransport.MaxResponseHeaderBytes
-	s.Transport.ProxyConnectHeader = oldTransport.ProxyConnectHeader
-	// Note: Consider adding other fields from http.Transport if necessary for Go 1.24.3 compatibility
-
-	s.Client.Transport = s.Transport
+	// Transport.Clone copies every field, including the ones added by newer Go versions.
+	s.Transport = s.Transport.Clone()
+	// the http.Client is shared with the parent too
+	s.safeModifyHttpClient()
+	if !s.customRoundTripper() {
+		s.Client.Transport = s.Transport
+	}
 }
 
 // Proxy function accepts a proxy url string to setup proxy url for any request.
@@ -660,9 +817,11 @@ func (s *SuperAgent) Proxy(proxyUrl string) *SuperAgent {
 	} else if proxyUrl == "" {
 		s.safeModifyTransport()
 		s.Transport.Proxy = nil
+		s.proxyURL = ""
 	} else {
 		s.safeModifyTransport()
 		s.Transport.Proxy = http.ProxyURL(parsedProxyUrl)
+		s.proxyURL = parsedProxyUrl.String()
 	}
 	return s
 }
@@ -689,12 +848,8 @@ func (s *SuperAgent) safeModifyHttpClient() {
 	if !s.isClone {
 		return
 	}
-	oldClient := s.Client
-	s.Client = &http.Client{}
-	s.Client.Jar = oldClient.Jar
-	s.Client.Transport = oldClient.Transport
-	s.Client.Timeout = oldClient.Timeout
-	s.Client.CheckRedirect = oldClient.CheckRedirect
+	client := *s.Client
+	s.Client = &client
 }
 
 // Timeout sets the timeout for the HTTP client.
@@ -809,7 +964,12 @@ func (s *SuperAgent) SendMap(content interface{}) *SuperAgent {
 
 // SendStruct (similar to SendString) returns SuperAgent's itself for any next chain and takes content interface{} as a parameter.
 // Its duty is to transfrom interface{} (implicitly always a struct) into s.Data (map[string]interface{}) which later changes into appropriate format such as json, form, text, etc. in the End() func.
+//
+// If the struct has `form` tags, only the tagged fields are sent and the body is a form (see BindStruct for the tag format).
 func (s *SuperAgent) SendStruct(content interface{}) *SuperAgent {
+	if hasStructTag(reflect.ValueOf(content), TagForm) {
+		return s.sendTagged(content)
+	}
 	if marshalContent, err := json.Marshal(content); err != nil {
 		s.Errors = append(s.Errors, err)
 	} else {
@@ -1034,74 +1194,97 @@ func (s *SuperAgent) SendFile(file interface{}, args ...interface{}) *SuperAgent
 	return s
 }
 
-func changeMapToURLValues(data map[string]interface{}) url.Values {
+// changeMapToURLValues turns s.Data into url.Values. Slices and arrays of any
+// primitive type are encoded according to arrayFormat (see SetArrayFormat).
+func changeMapToURLValues(data map[string]interface{}, arrayFormat string) url.Values {
 	var newUrlValues = url.Values{}
 	for k, v := range data {
-		switch val := v.(type) {
-		case string:
-			newUrlValues.Add(k, val)
-		case bool:
-			newUrlValues.Add(k, strconv.FormatBool(val))
-		// if a number, change to string
+		if value, ok := formatScalar(reflect.ValueOf(v)); ok {
+			newUrlValues.Add(k, value)
+			continue
+		}
+		// these slices are used in practice like sending a struct, e.g. []interface{} from SendStruct
+		// or []string from SendString, but any slice or array of primitives works
+		if elements, ok := formatScalarSlice(reflect.ValueOf(v)); ok {
+			addArrayValues(newUrlValues, k, elements, arrayFormat)
+		}
+		// TODO add maps, nested structs, ...
+	}
+	return newUrlValues
+}
+
+// formatScalar returns the string form of a primitive value: strings, booleans,
+// json.Number and every int, uint and float kind. Pointers and interfaces are followed.
+func formatScalar(v reflect.Value) (string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.String:
 		// json.Number used to protect against a wrong (for GoRequest) default conversion
 		// which always converts number to float64.
 		// This type is caused by using Decoder.UseNumber()
-		case json.Number:
-			newUrlValues.Add(k, string(val))
-		case int:
-			newUrlValues.Add(k, strconv.FormatInt(int64(val), 10))
-		// TODO add all other int-Types (int8, int16, ...)
-		case float64:
-			newUrlValues.Add(k, strconv.FormatFloat(float64(val), 'f', -1, 64))
-		case float32:
-			newUrlValues.Add(k, strconv.FormatFloat(float64(val), 'f', -1, 64))
-		// following slices are mostly needed for tests
-		case []string:
-			for _, element := range val {
-				newUrlValues.Add(k, element)
-			}
-		case []int:
-			for _, element := range val {
-				newUrlValues.Add(k, strconv.FormatInt(int64(element), 10))
-			}
-		case []bool:
-			for _, element := range val {
-				newUrlValues.Add(k, strconv.FormatBool(element))
-			}
-		case []float64:
-			for _, element := range val {
-				newUrlValues.Add(k, strconv.FormatFloat(float64(element), 'f', -1, 64))
-			}
-		case []float32:
-			for _, element := range val {
-				newUrlValues.Add(k, strconv.FormatFloat(float64(element), 'f', -1, 64))
-			}
-		// these slices are used in practice like sending a struct
-		case []interface{}:
+		return v.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(v.Uint(), 10), true
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), true
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), true
+	}
+	return "", false
+}
 
-			if len(val) <= 0 {
-				continue
-			}
+// formatScalarSlice returns the string form of every element of a slice or array.
+// It fails if v is not a slice or array or if any element is not a primitive.
+func formatScalarSlice(v reflect.Value) ([]string, bool) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, false
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, false
+	}
+	elements := make([]string, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		element, ok := formatScalar(v.Index(i))
+		if !ok {
+			return nil, false
+		}
+		elements = append(elements, element)
+	}
+	return elements, true
+}
 
-			switch val[0].(type) {
-			case string:
-				for _, element := range val {
-					newUrlValues.Add(k, element.(string))
-				}
-			case bool:
-				for _, element := range val {
-					newUrlValues.Add(k, strconv.FormatBool(element.(bool)))
-				}
-			case json.Number:
-				for _, element := range val {
-					newUrlValues.Add(k, string(element.(json.Number)))
-				}
-			}
-		default:
-			// TODO add ptr, arrays, ...
+// addArrayValues adds elements under key to values using the given array format.
+// Empty slices add nothing.
+func addArrayValues(values url.Values, key string, elements []string, arrayFormat string) {
+	if len(elements) == 0 {
+		return
+	}
+	switch arrayFormat {
+	case ArrayFormatBrackets:
+		for _, element := range elements {
+			values.Add(key+"[]", element)
+		}
+	case ArrayFormatComma:
+		values.Add(key, strings.Join(elements, ","))
+	case ArrayFormatPipe:
+		values.Add(key, strings.Join(elements, "|"))
+	default:
+		for _, element := range elements {
+			values.Add(key, element)
 		}
 	}
-	return newUrlValues
 }
 
 // End is the most important function that you need to call when ending the chain. The request won't proceed without calling it.
@@ -1150,15 +1333,32 @@ func (s *SuperAgent) EndBytes(callback ...func(response Response, body []byte, e
 		body []byte
 	)
 
+	var labels MetricLabels
+	start := time.Now()
+	defer func() { s.logResult(resp, errs, start, false) }()
+	if s.metrics != nil {
+		labels = s.metricLabels()
+		s.metrics.RequestStarted(labels)
+	}
 	for {
 		resp, body, errs = s.getResponseBytes()
 		if errs != nil {
+			if s.metrics != nil {
+				s.metrics.RequestFinished(labels.withStatus(nil), time.Since(start))
+			}
 			return nil, nil, errs
 		}
 		if s.isRetryableRequest(resp) {
 			resp.Header.Set("Retry-Count", strconv.Itoa(s.Retryable.Attempt))
 			break
 		}
+		s.logResult(resp, nil, start, true)
+		if s.metrics != nil {
+			s.metrics.RequestRetried(labels.withStatus(resp))
+		}
+	}
+	if s.metrics != nil {
+		s.metrics.RequestFinished(labels.withStatus(resp), time.Since(start))
 	}
 
 	respCallback := *resp
@@ -1212,35 +1412,16 @@ func (s *SuperAgent) EndStruct(v interface{}, callback ...func(response Response
 	return resp, body, nil
 }
 
-func (s *SuperAgent) getResponseBytes() (Response, []byte, []error) {
+func (s *SuperAgent) getResponseBytes() (resp Response, body []byte, errs []error) {
 	var (
-		req  *http.Request
-		err  error
-		resp Response
+		req *http.Request
+		err error
 	)
 	// check whether there is an error. if yes, return all errors
 	if len(s.Errors) != 0 {
 		return nil, nil, s.Errors
 	}
-	// check if there is forced type
-	switch s.ForceType {
-	case TypeJSON, TypeForm, TypeXML, TypeText, TypeMultipart:
-		s.TargetType = s.ForceType
-		// If forcetype is not set, check whether user set Content-Type header.
-		// If yes, also bounce to the correct supported TargetType automatically.
-	default:
-		contentType := s.Header.Get("Content-Type")
-		for k, v := range Types {
-			if contentType == v {
-				s.TargetType = k
-			}
-		}
-	}
-
-	// if slice and map get mixed, let's bounce to rawstring
-	if len(s.Data) != 0 && len(s.SliceData) != 0 {
-		s.BounceToRawString = true
-	}
+	s.resolveTargetType()
 
 	// Make Request
 	req, err = s.MakeRequest()
@@ -1249,60 +1430,118 @@ func (s *SuperAgent) getResponseBytes() (Response, []byte, []error) {
 		return nil, nil, s.Errors
 	}
 
-	// Set Transport
-	if !DisableTransportSwap {
-		s.Client.Transport = s.Transport
+	// Start the span of this attempt
+	if s.tracer != nil {
+		var span Span
+		req, span = s.startSpan(req)
+		defer func() { endSpan(span, resp, errs) }()
+	}
+
+	// Set Transport. The client may be shared with clones, so only write it if it changed.
+	client := s.Client
+	if !DisableTransportSwap && !s.customRoundTripper() {
+		if shared := s.sharedTransport(); shared != nil {
+			sharedClient := *s.Client
+			sharedClient.Transport = shared
+			client = &sharedClient
+		} else if s.Client.Transport != http.RoundTripper(s.Transport) {
+			s.Client.Transport = s.Transport
+		}
+	}
+	if s.cassette != nil {
+		client = withTransport(client, &cassetteTransport{base: transportOf(client), cassette: s.cassette, policy: s.redaction()})
+	}
+	if s.faultInjector != nil {
+		client = withTransport(client, s.faultInjector.RoundTripper(transportOf(client)))
+	}
+	if s.harRecorder != nil {
+		client = withTransport(client, &harTransport{base: transportOf(client), recorder: s.harRecorder, policy: s.redaction()})
 	}
 
 	// Log details of this request
 	if s.Debug {
-		dump, err := httputil.DumpRequest(req, true)
-		s.logger.SetPrefix("[http] ")
-		if err != nil {
-			s.logger.Println("Error:", err)
-		} else {
-			s.logger.Printf("HTTP Request: %s", string(dump))
-		}
+		dump, err := s.dumpRequest(req)
+		s.logDebug("[http] ", "HTTP Request", "dump", dump, err)
 	}
 
 	// Display CURL command line
 	if s.CurlCommand {
-		curl, err := http2curl.GetCurlCommand(req)
-		s.logger.SetPrefix("[curl] ")
-		if err != nil {
-			s.logger.Println("Error:", err)
-		} else {
-			s.logger.Printf("CURL command line: %s", curl)
-		}
+		curl, err := s.redaction().curlCommand(req)
+		s.logDebug("[curl] ", "CURL command line", "curl", curl, err)
 	}
 
 	// Send request
-	resp, err = s.Client.Do(req)
+	var cancel context.CancelFunc
+	if s.bodyIdleTimeout > 0 {
+		var ctx context.Context
+		ctx, cancel = context.WithCancel(req.Context())
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+	var tracer *requestTracer
+	if s.trace {
+		tracer = newRequestTracer()
+		req = tracer.withTrace(req)
+	}
+	resp, err = client.Do(req)
 	if err != nil {
 		s.Errors = append(s.Errors, err)
 		return nil, nil, s.Errors
 	}
-	defer resp.Body.Close()
-
-	// Log details of this response
-	if s.Debug {
-		dump, err := httputil.DumpResponse(resp, true)
-		if nil != err {
-			s.logger.Println("Error:", err)
-		} else {
-			s.logger.Printf("HTTP Response: %s", string(dump))
-		}
+	if cancel != nil {
+		resp.Body = newIdleTimeoutBody(resp.Body, s.bodyIdleTimeout, cancel)
 	}
+	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err = ioutil.ReadAll(resp.Body)
 	// Reset resp.Body so it can be use again
 	resp.Body = ioutil.NopCloser(bytes.NewBuffer(body))
 	if err != nil {
 		return nil, nil, []error{err}
 	}
+
+	// Log details of this response
+	if s.Debug {
+		dump, err := s.dumpResponse(resp, body)
+		s.logDebug("[http] ", "HTTP Response", "dump", dump, err)
+	}
+
+	// Log timings of this request
+	if tracer != nil {
+		info := tracer.done()
+		resp.Body = &tracedBody{ReadCloser: resp.Body, info: info}
+		if s.Debug && s.slogger != nil {
+			s.log(slog.LevelDebug, "HTTP Trace", slog.String("method", s.Method), traceAttrs(info))
+		} else if s.Debug {
+			s.logger.Printf("[http] HTTP Trace: %s", info)
+		}
+	}
 	return resp, body, nil
 }
 
+// resolveTargetType sets the TargetType from the forced type or the Content-Type header.
+func (s *SuperAgent) resolveTargetType() {
+	// check if there is forced type
+	switch s.ForceType {
+	case TypeJSON, TypeForm, TypeXML, TypeText, TypeMultipart:
+		s.TargetType = s.ForceType
+		// If forcetype is not set, check whether user set Content-Type header.
+		// If yes, also bounce to the correct supported TargetType automatically.
+	default:
+		contentType := s.Header.Get("Content-Type")
+		for k, v := range Types {
+			if contentType == v {
+				s.TargetType = k
+			}
+		}
+	}
+
+	// if slice and map get mixed, let's bounce to rawstring
+	if len(s.Data) != 0 && len(s.SliceData) != 0 {
+		s.BounceToRawString = true
+	}
+}
+
 func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 	var (
 		req           *http.Request
@@ -1349,7 +1588,12 @@ func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 		if s.BounceToRawString || len(s.SliceData) != 0 {
 			contentForm = []byte(s.RawString)
 		} else {
-			formData := changeMapToURLValues(s.Data)
+			formData := changeMapToURLValues(s.Data, s.arrayFormat())
+			for k, values := range s.FormData {
+				for _, value := range values {
+					formData.Add(k, value)
+				}
+			}
 			contentForm = []byte(formData.Encode())
 		}
 		if len(contentForm) != 0 {
@@ -1383,7 +1627,7 @@ func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 		}
 
 		if len(s.Data) != 0 {
-			formData := changeMapToURLValues(s.Data)
+			formData := changeMapToURLValues(s.Data, s.arrayFormat())
 			for key, values := range formData {
 				for _, value := range values {
 					fw, _ := mw.CreateFormField(key)
@@ -1393,6 +1637,16 @@ func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 			contentReader = buf
 		}
 
+		if len(s.FormData) != 0 {
+			for key, values := range s.FormData {
+				for _, value := range values {
+					fw, _ := mw.CreateFormField(key)
+					fw.Write([]byte(value))
+				}
+			}
+			contentReader = buf
+		}
+
 		if len(s.SliceData) != 0 {
 			fieldName := s.Header.Get("json_fieldname")
 			if fieldName == "" {
@@ -1432,7 +1686,14 @@ func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 		return nil, errors.New("TargetType '" + s.TargetType + "' could not be determined")
 	}
 
-	if req, err = http.NewRequest(s.Method, s.Url, contentReader); err != nil {
+	targetUrl := resolveURL(s.baseURL, s.Url)
+	if s.pathParams != nil {
+		if targetUrl, err = expandURITemplate(targetUrl, s.pathParams); err != nil {
+			return nil, err
+		}
+	}
+
+	if req, err = http.NewRequest(s.Method, targetUrl, contentReader); err != nil {
 		return nil, err
 	}
 
@@ -1464,6 +1725,12 @@ func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 			q.Add(k, vv)
 		}
 	}
+	// the defaults of a Client, unless the request has its own values
+	for k, v := range s.defaultQuery {
+		if _, ok := q[k]; !ok {
+			q[k] = append([]string(nil), v...)
+		}
+	}
 	req.URL.RawQuery = q.Encode()
 
 	// Add basic auth
@@ -1480,13 +1747,13 @@ func (s *SuperAgent) MakeRequest() (*http.Request, error) {
 }
 
 // AsCurlCommand returns a string representing the runnable `curl' command
-// version of the request.
+// version of the request. Secrets are redacted, see SetRedaction.
 func (s *SuperAgent) AsCurlCommand() (string, error) {
 	req, err := s.MakeRequest()
 	if err != nil {
 		return "", err
 	}
-	cmd, err := http2curl.GetCurlCommand(req)
+	cmd, err := s.redaction().curlCommand(req)
 	if err != nil {
 		return "", err
 	}
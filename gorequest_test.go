@@ -127,7 +127,7 @@ func TestChangeMapToURLValues(t *testing.T) {
 		"ba": []bool{true, false},
 	}
 
-	urlValues := changeMapToURLValues(data)
+	urlValues := changeMapToURLValues(data, ArrayFormatRepeat)
 
 	var (
 		s  string
@@ -206,6 +206,31 @@ func TestChangeMapToURLValues(t *testing.T) {
 	}
 }
 
+// Test for changeMapToURLValues with every array format and primitive slice type
+func TestChangeMapToURLValuesArrayFormat(t *testing.T) {
+	data := map[string]interface{}{
+		"i8":  []int8{1, 2},
+		"u":   []uint{3, 4},
+		"f32": []float32{1.5, 2.25},
+		"num": []interface{}{json.Number("5"), json.Number("6")},
+		"arr": [2]string{"a", "b"},
+	}
+	var cases = []struct {
+		format string
+		want   string
+	}{
+		{ArrayFormatRepeat, "arr=a&arr=b&f32=1.5&f32=2.25&i8=1&i8=2&num=5&num=6&u=3&u=4"},
+		{ArrayFormatBrackets, "arr%5B%5D=a&arr%5B%5D=b&f32%5B%5D=1.5&f32%5B%5D=2.25&i8%5B%5D=1&i8%5B%5D=2&num%5B%5D=5&num%5B%5D=6&u%5B%5D=3&u%5B%5D=4"},
+		{ArrayFormatComma, "arr=a%2Cb&f32=1.5%2C2.25&i8=1%2C2&num=5%2C6&u=3%2C4"},
+		{ArrayFormatPipe, "arr=a%7Cb&f32=1.5%7C2.25&i8=1%7C2&num=5%7C6&u=3%7C4"},
+	}
+	for _, c := range cases {
+		if got := changeMapToURLValues(data, c.format).Encode(); got != c.want {
+			t.Error(fmt.Sprintf("Expected %q for array format %q | but got %q", c.want, c.format, got))
+		}
+	}
+}
+
 // Test for Make request
 func TestMakeRequest(t *testing.T) {
 	var err error
@@ -1903,6 +1928,70 @@ func TestQueryFunc(t *testing.T) {
 		End()
 }
 
+func TestQueryArrayFormat(t *testing.T) {
+	var rawQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+	}))
+	defer ts.Close()
+
+	ids := struct {
+		Ids []int `json:"ids"`
+	}{[]int{1, 2}}
+
+	New().Get(ts.URL).Query(ids).End()
+	if rawQuery != "ids=1&ids=2" {
+		t.Error(fmt.Sprintf("Expected repeat query %q | but got %q", "ids=1&ids=2", rawQuery))
+	}
+
+	request := New().SetArrayFormat(ArrayFormatBrackets)
+	request.Get(ts.URL).Query(map[string]interface{}{"ids": []string{"a", "b"}}).End()
+	if rawQuery != "ids%5B%5D=a&ids%5B%5D=b" {
+		t.Error(fmt.Sprintf("Expected brackets query %q | but got %q", "ids%5B%5D=a&ids%5B%5D=b", rawQuery))
+	}
+
+	// per-request format only applies to the current request
+	request.Get(ts.URL).UseArrayFormat(ArrayFormatPipe).Query(ids).End()
+	if rawQuery != "ids=1%7C2" {
+		t.Error(fmt.Sprintf("Expected pipe query %q | but got %q", "ids=1%7C2", rawQuery))
+	}
+	request.Get(ts.URL).Query(ids).End()
+	if rawQuery != "ids%5B%5D=1&ids%5B%5D=2" {
+		t.Error(fmt.Sprintf("Expected brackets query %q | but got %q", "ids%5B%5D=1&ids%5B%5D=2", rawQuery))
+	}
+
+	// the format applies to later Query calls only
+	New().Get(ts.URL).Query(ids).UseArrayFormat(ArrayFormatComma).Query(map[string]interface{}{"tags": []string{"a", "b"}}).End()
+	if rawQuery != "ids=1&ids=2&tags=a%2Cb" {
+		t.Error(fmt.Sprintf("Expected repeat then comma query %q | but got %q", "ids=1&ids=2&tags=a%2Cb", rawQuery))
+	}
+
+	_, _, errs := New().Get(ts.URL).SetArrayFormat("semicolon").End()
+	if errs == nil {
+		t.Error("Expected error for unknown array format")
+	}
+}
+
+func TestSendFormArrayFormat(t *testing.T) {
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer ts.Close()
+
+	New().SetArrayFormat(ArrayFormatComma).
+		Post(ts.URL).
+		Type(TypeForm).
+		Send(struct {
+			Ids []int64 `json:"ids"`
+		}{[]int64{7, 8}}).
+		End()
+	if body != "ids=7%2C8" {
+		t.Error(fmt.Sprintf("Expected form body %q | but got %q", "ids=7%2C8", body))
+	}
+}
+
 // TODO: more tests on redirect
 func TestRedirectPolicyFunc(t *testing.T) {
 	redirectSuccess := false
@@ -0,0 +1,385 @@
+// Package gorequesttest provides a mock transport to unit test code using gorequest,
+// without opening sockets:
+//
+//	func TestCreateUser(t *testing.T) {
+//	  mock := gorequesttest.NewMock(t)
+//	  mock.On("POST", "/users").
+//	    WithJSON(`{"name":"gopher"}`).
+//	    RespondJSON(201, map[string]interface{}{"id": 1})
+//
+//	  resp, body, errs := gorequest.New().
+//	    SetRoundTripper(mock).
+//	    Post("https://api.example.com/users").
+//	    Send(`{"name":"gopher"}`).
+//	    End()
+//	  // the test fails if POST /users wasn't called, or if other requests were sent
+//	}
+package gorequesttest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// TB is the subset of testing.TB used by Mock.
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Cleanup(func())
+}
+
+// A Mock is an http.RoundTripper answering the requests matching its expectations.
+// Requests matching no expectation fail the test, and get an error. It is safe for
+// concurrent use.
+type Mock struct {
+	t            TB
+	mu           sync.Mutex
+	expectations []*Expectation
+}
+
+// NewMock returns a Mock asserting its expectations were met when the test ends.
+func NewMock(t TB) *Mock {
+	m := &Mock{t: t}
+	t.Cleanup(m.AssertExpectations)
+	return m
+}
+
+// An Expectation describes the requests a Mock answers, and the answer.
+type Expectation struct {
+	method   string
+	url      string
+	query    map[string]string
+	header   map[string]string
+	body     *string
+	json     interface{}
+	matchers []func(req *http.Request, body []byte) bool
+
+	status     int
+	respHeader http.Header
+	respBody   []byte
+	err        error
+
+	times int // -1 for any number
+	calls int
+}
+
+// On adds an expectation for the requests with method to url. url is either a path, or an
+// absolute url without query. The expectation responds 200 with an empty body unless told
+// otherwise, and is expected to be met once.
+func (m *Mock) On(method, url string) *Expectation {
+	e := &Expectation{
+		method:     strings.ToUpper(method),
+		url:        url,
+		query:      map[string]string{},
+		header:     map[string]string{},
+		status:     http.StatusOK,
+		respHeader: http.Header{},
+		times:      1,
+	}
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// WithQuery expects the query parameter name to equal value.
+func (e *Expectation) WithQuery(name, value string) *Expectation {
+	e.query[name] = value
+	return e
+}
+
+// WithHeader expects the header name to equal value.
+func (e *Expectation) WithHeader(name, value string) *Expectation {
+	e.header[name] = value
+	return e
+}
+
+// WithBody expects the body to equal body.
+func (e *Expectation) WithBody(body string) *Expectation {
+	e.body = &body
+	return e
+}
+
+// WithJSON expects the body to be JSON equal to v, whatever the spacing and key order.
+// v is either a JSON string, or a value marshaled to JSON.
+func (e *Expectation) WithJSON(v interface{}) *Expectation {
+	e.json = normalizeJSON(v)
+	return e
+}
+
+// Matching expects match to return true.
+func (e *Expectation) Matching(match func(req *http.Request, body []byte) bool) *Expectation {
+	e.matchers = append(e.matchers, match)
+	return e
+}
+
+// Respond sets the status and body of the response.
+func (e *Expectation) Respond(status int, body string) *Expectation {
+	e.status = status
+	e.respBody = []byte(body)
+	return e
+}
+
+// RespondJSON sets the status and JSON body of the response. v is either a JSON string,
+// or a value marshaled to JSON.
+func (e *Expectation) RespondJSON(status int, v interface{}) *Expectation {
+	e.status = status
+	if s, ok := v.(string); ok {
+		e.respBody = []byte(s)
+	} else {
+		e.respBody, _ = json.Marshal(v)
+	}
+	e.respHeader.Set("Content-Type", "application/json")
+	return e
+}
+
+// RespondHeader adds a header to the response.
+func (e *Expectation) RespondHeader(name, value string) *Expectation {
+	e.respHeader.Add(name, value)
+	return e
+}
+
+// RespondError makes the request fail with err, as a transport error would.
+func (e *Expectation) RespondError(err error) *Expectation {
+	e.err = err
+	return e
+}
+
+// Times sets how many times the expectation is expected to be met.
+func (e *Expectation) Times(n int) *Expectation {
+	e.times = n
+	return e
+}
+
+// AnyTimes lets the expectation be met any number of times, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.times = -1
+	return e
+}
+
+func (e *Expectation) String() string {
+	return e.method + " " + e.url
+}
+
+// RoundTrip answers req with the first expectation it matches which isn't met yet.
+func (m *Mock) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+	}
+
+	m.mu.Lock()
+	var matched *Expectation
+	for _, e := range m.expectations {
+		if (e.times < 0 || e.calls < e.times) && len(e.mismatches(req, body)) == 0 {
+			matched = e
+			e.calls++
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	if matched == nil {
+		m.t.Helper()
+		report := m.unmatchedReport(req, body)
+		m.t.Errorf("%s", report)
+		return nil, errors.New(report)
+	}
+	if matched.err != nil {
+		return nil, matched.err
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", matched.status, http.StatusText(matched.status)),
+		StatusCode:    matched.status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        matched.respHeader.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(matched.respBody)),
+		ContentLength: int64(len(matched.respBody)),
+		Request:       req,
+	}, nil
+}
+
+// AssertExpectations fails the test for every expectation not met. NewMock calls it when
+// the test ends.
+func (m *Mock) AssertExpectations() {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.expectations {
+		if e.times >= 0 && e.calls != e.times {
+			m.t.Errorf("gorequesttest: expected %s to be called %d times, but it was called %d times", e, e.times, e.calls)
+		}
+	}
+}
+
+// mismatches returns the differences between req and the expectation.
+func (e *Expectation) mismatches(req *http.Request, body []byte) []string {
+	var diffs []string
+	if e.method != req.Method {
+		diffs = append(diffs, fmt.Sprintf("method: expected %s, got %s", e.method, req.Method))
+	}
+	if actual := requestURL(req, e.url); actual != e.url {
+		diffs = append(diffs, fmt.Sprintf("url: expected %s, got %s", e.url, actual))
+	}
+	query := req.URL.Query()
+	for _, name := range sortedKeys(e.query) {
+		if actual, ok := query[name]; !ok || len(actual) != 1 || actual[0] != e.query[name] {
+			diffs = append(diffs, fmt.Sprintf("query %s: expected %q, got %q", name, e.query[name], actual))
+		}
+	}
+	for _, name := range sortedKeys(e.header) {
+		if actual := req.Header.Get(name); actual != e.header[name] {
+			diffs = append(diffs, fmt.Sprintf("header %s: expected %q, got %q", name, e.header[name], actual))
+		}
+	}
+	if e.body != nil && *e.body != string(body) {
+		diffs = append(diffs, "body:\n"+diff(*e.body, string(body)))
+	}
+	if e.json != nil {
+		var actual interface{}
+		if err := json.Unmarshal(body, &actual); err != nil || !reflect.DeepEqual(e.json, actual) {
+			diffs = append(diffs, "json body:\n"+diff(indentJSON(e.json), indentBody(body)))
+		}
+	}
+	for i, match := range e.matchers {
+		if !match(req, body) {
+			diffs = append(diffs, fmt.Sprintf("matcher %d: returned false", i+1))
+		}
+	}
+	return diffs
+}
+
+// unmatchedReport describes req, and how it differs from the closest expectations.
+func (m *Mock) unmatchedReport(req *http.Request, body []byte) string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var report strings.Builder
+	fmt.Fprintf(&report, "gorequesttest: unexpected request %s %s", req.Method, req.URL)
+	if len(m.expectations) == 0 {
+		report.WriteString("\nno expectation registered")
+		return report.String()
+	}
+	// compare with the expectations differing the least
+	best := -1
+	for _, e := range m.expectations {
+		if n := len(e.mismatches(req, body)); best < 0 || n < best {
+			best = n
+		}
+	}
+	for _, e := range m.expectations {
+		diffs := e.mismatches(req, body)
+		if len(diffs) != best {
+			continue
+		}
+		if len(diffs) == 0 {
+			fmt.Fprintf(&report, "\nexpectation %s matches, but was already called %d times", e, e.calls)
+			continue
+		}
+		fmt.Fprintf(&report, "\nclosest expectation %s:", e)
+		for _, d := range diffs {
+			report.WriteString("\n  " + strings.Replace(d, "\n", "\n  ", -1))
+		}
+	}
+	return report.String()
+}
+
+// requestURL returns the url of req, as a path if expected is a path.
+func requestURL(req *http.Request, expected string) string {
+	if strings.HasPrefix(expected, "/") {
+		return req.URL.Path
+	}
+	u := *req.URL
+	u.RawQuery = ""
+	u.Fragment = ""
+	return u.String()
+}
+
+// normalizeJSON returns v decoded from JSON, to be compared with reflect.DeepEqual.
+func normalizeJSON(v interface{}) interface{} {
+	data, ok := v.(string)
+	if !ok {
+		marshaled, err := json.Marshal(v)
+		if err != nil {
+			panic(fmt.Sprintf("gorequesttest: can't marshal %v: %v", v, err))
+		}
+		data = string(marshaled)
+	}
+	var normalized interface{}
+	if err := json.Unmarshal([]byte(data), &normalized); err != nil {
+		panic(fmt.Sprintf("gorequesttest: invalid JSON %q: %v", data, err))
+	}
+	return normalized
+}
+
+func indentJSON(v interface{}) string {
+	data, _ := json.MarshalIndent(v, "", "  ")
+	return string(data)
+}
+
+func indentBody(body []byte) string {
+	var buf bytes.Buffer
+	if json.Indent(&buf, body, "", "  ") != nil {
+		return string(body)
+	}
+	return buf.String()
+}
+
+// diff returns a line diff of expected and actual, prefixing the lines only in expected
+// with "-" and the lines only in actual with "+".
+func diff(expected, actual string) string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	// longest common subsequence
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+	var out []string
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out = append(out, "  "+a[i])
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out = append(out, "- "+a[i])
+			i++
+		default:
+			out = append(out, "+ "+b[j])
+			j++
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
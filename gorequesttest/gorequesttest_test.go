@@ -0,0 +1,88 @@
+package gorequesttest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/parnurzeal/gorequest"
+	"github.com/pkg/errors"
+)
+
+// A fakeTB records the failures instead of failing the test.
+type fakeTB struct {
+	errors   []string
+	cleanups []func()
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func (t *fakeTB) Cleanup(f func()) { t.cleanups = append(t.cleanups, f) }
+
+func (t *fakeTB) end() {
+	for _, f := range t.cleanups {
+		f()
+	}
+}
+
+func TestMock(t *testing.T) {
+	mock := NewMock(t)
+	mock.On("POST", "/users").
+		WithHeader("X-Request-Id", "1").
+		WithJSON(map[string]interface{}{"name": "gopher", "tags": []string{"a"}}).
+		RespondJSON(http.StatusCreated, map[string]interface{}{"id": 1}).
+		RespondHeader("Location", "/users/1")
+	mock.On("GET", "https://api.example.com/users").WithQuery("page", "2").Respond(http.StatusOK, "[]").Times(2)
+	mock.On("DELETE", "/users/1").RespondError(errors.New("connection reset"))
+
+	client := gorequest.NewClient("https://api.example.com").SetRoundTripper(mock)
+	resp, body, errs := client.Post("/users").
+		Set("X-Request-Id", "1").
+		Send(`{"tags": ["a"], "name": "gopher"}`).
+		End()
+	if errs != nil || resp.StatusCode != http.StatusCreated || body != `{"id":1}` || resp.Header.Get("Location") != "/users/1" {
+		t.Error(fmt.Sprintf("Expected mocked response | but got %v, %q, %v", resp, body, errs))
+	}
+	for i := 0; i < 2; i++ {
+		if _, body, errs := client.Get("/users").Query("page=2").End(); errs != nil || body != "[]" {
+			t.Error(fmt.Sprintf("Expected mocked list | but got %q, %v", body, errs))
+		}
+	}
+	if _, _, errs := client.Delete("/users/1").End(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "connection reset") {
+		t.Error(fmt.Sprintf("Expected mocked transport error | but got %v", errs))
+	}
+}
+
+func TestMockFailures(t *testing.T) {
+	tb := &fakeTB{}
+	mock := NewMock(tb)
+	mock.On("POST", "/users").WithJSON(`{"name":"gopher","admin":false}`)
+	mock.On("GET", "/health").AnyTimes()
+
+	request := gorequest.New().SetRoundTripper(mock)
+	_, _, errs := request.Post("https://api.example.com/users").Send(`{"name":"gopher","admin":true}`).End()
+	if len(errs) != 1 || len(tb.errors) != 1 {
+		t.Fatal(fmt.Sprintf("Expected unmatched request to fail | but got %v, %q", errs, tb.errors))
+	}
+	expected := `gorequesttest: unexpected request POST https://api.example.com/users
+closest expectation POST /users:
+  json body:
+    {
+  -   "admin": false,
+  +   "admin": true,
+      "name": "gopher"
+    }`
+	if tb.errors[0] != expected {
+		t.Error(fmt.Sprintf("Expected report\n%s\n | but got\n%s", expected, tb.errors[0]))
+	}
+
+	tb.end()
+	if len(tb.errors) != 2 || tb.errors[1] != "gorequesttest: expected POST /users to be called 1 times, but it was called 0 times" {
+		t.Error(fmt.Sprintf("Expected unmet expectation | but got %q", tb.errors))
+	}
+}
@@ -0,0 +1,447 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HAR is an HTTP Archive 1.2, see http://www.softwareishard.com/blog/har-12-spec/.
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// A HAREntry is an exchange: one attempt of a request, or one redirect.
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARCookie    `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+	// Error is set if no response was received, as done by browsers.
+	Error string `json:"_error,omitempty"`
+}
+
+type HARCookie struct {
+	Name     string     `json:"name"`
+	Value    string     `json:"value"`
+	Path     string     `json:"path,omitempty"`
+	Domain   string     `json:"domain,omitempty"`
+	Expires  *time.Time `json:"expires,omitempty"`
+	HTTPOnly bool       `json:"httpOnly,omitempty"`
+	Secure   bool       `json:"secure,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []HARNameValue `json:"params,omitempty"`
+	Comment  string         `json:"comment,omitempty"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// HARTimings are in milliseconds, -1 for the steps which didn't happen.
+type HARTimings struct {
+	Blocked float64 `json:"blocked"`
+	DNS     float64 `json:"dns"`
+	Connect float64 `json:"connect"`
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+	SSL     float64 `json:"ssl"`
+}
+
+// A HARRecorder records exchanges in an HTTP Archive, see RecordHAR.
+// It is safe for concurrent use.
+type HARRecorder struct {
+	mu      sync.Mutex
+	entries []HAREntry
+}
+
+// NewHARRecorder returns an empty HARRecorder.
+func NewHARRecorder() *HARRecorder {
+	return &HARRecorder{}
+}
+
+// RecordHAR records every exchange of the SuperAgent, retries and redirects included, in
+// recorder. Secrets are redacted, see SetRedaction. The archive loads in the network tab
+// of browser devtools:
+//
+//	recorder := gorequest.NewHARRecorder()
+//	gorequest.New().RecordHAR(recorder).Get("https://example.com").End()
+//	recorder.WriteFile("example.har")
+func (s *SuperAgent) RecordHAR(recorder *HARRecorder) *SuperAgent {
+	s.harRecorder = recorder
+	return s
+}
+
+// RecordHAR returns a Client recording its exchanges, see SuperAgent.RecordHAR.
+func (c *Client) RecordHAR(recorder *HARRecorder) *Client {
+	return c.with(func(s *SuperAgent) { s.RecordHAR(recorder) })
+}
+
+// HAR returns the archive of the exchanges recorded so far, ordered by start time.
+func (r *HARRecorder) HAR() HAR {
+	r.mu.Lock()
+	entries := append([]HAREntry{}, r.entries...)
+	r.mu.Unlock()
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].StartedDateTime.Before(entries[j].StartedDateTime)
+	})
+	return HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "gorequest"},
+		Entries: entries,
+	}}
+}
+
+// WriteTo writes the archive as JSON to w.
+func (r *HARRecorder) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(r.HAR(), "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// WriteFile writes the archive as JSON to the named file.
+func (r *HARRecorder) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := r.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Reset forgets the exchanges recorded so far.
+func (r *HARRecorder) Reset() {
+	r.mu.Lock()
+	r.entries = nil
+	r.mu.Unlock()
+}
+
+func (r *HARRecorder) add(entry HAREntry) {
+	r.mu.Lock()
+	r.entries = append(r.entries, entry)
+	r.mu.Unlock()
+}
+
+// transportOf returns the transport of client, or the default one.
+func transportOf(client *http.Client) http.RoundTripper {
+	if client.Transport == nil {
+		return http.DefaultTransport
+	}
+	return client.Transport
+}
+
+// withTransport returns a copy of client sending its requests through transport.
+func withTransport(client *http.Client, transport http.RoundTripper) *http.Client {
+	c := *client
+	c.Transport = transport
+	return &c
+}
+
+// A harTransport records the exchanges sent through it.
+type harTransport struct {
+	base     http.RoundTripper
+	recorder *HARRecorder
+	policy   *RedactionPolicy
+}
+
+func (t *harTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	started := time.Now()
+	tracer := &requestTracer{start: started}
+	traced := tracer.withTrace(req)
+
+	// read a copy of the request body if possible, else record it while it is sent
+	var (
+		reqBody []byte
+		capture *captureBody
+	)
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			reqBody, _ = ioutil.ReadAll(body)
+			body.Close()
+		}
+	} else if req.Body != nil && req.Body != http.NoBody {
+		capture = &captureBody{ReadCloser: req.Body}
+		traced.Body = capture
+	}
+
+	resp, err := t.base.RoundTrip(traced)
+	if capture != nil {
+		reqBody = capture.bytes()
+	}
+	if err != nil {
+		entry := t.entry(req, reqBody, tracer, started, time.Now())
+		entry.Response = HARResponse{
+			HTTPVersion: req.Proto, Cookies: []HARCookie{}, Headers: []HARNameValue{}, HeadersSize: -1, BodySize: -1,
+			Error: err.Error(),
+		}
+		t.recorder.add(entry)
+		return nil, err
+	}
+	body := &captureBody{ReadCloser: resp.Body}
+	body.onClose = func() {
+		entry := t.entry(req, reqBody, tracer, started, time.Now())
+		entry.Response = t.response(resp, body.bytes())
+		t.recorder.add(entry)
+	}
+	resp.Body = body
+	return resp, nil
+}
+
+// entry returns the entry of req, without response.
+func (t *harTransport) entry(req *http.Request, body []byte, tracer *requestTracer, started, ended time.Time) HAREntry {
+	entry := HAREntry{
+		StartedDateTime: started,
+		Request: HARRequest{
+			Method:      req.Method,
+			URL:         t.policy.redactURL(req.URL).String(),
+			HTTPVersion: req.Proto,
+			Cookies:     t.cookies(req.Cookies(), "Cookie"),
+			Headers:     harHeaders(t.policy.redactHeader(req.Header)),
+			QueryString: []HARNameValue{},
+			HeadersSize: -1,
+			BodySize:    len(body),
+		},
+	}
+	query, _ := url.ParseQuery(t.policy.redactQuery(req.URL.RawQuery))
+	entry.Request.QueryString = harValues(query)
+	if len(body) != 0 {
+		contentType := req.Header.Get("Content-Type")
+		body = t.policy.redactBody(contentType, body)
+		postData := &HARPostData{MimeType: contentType}
+		if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType == "application/x-www-form-urlencoded" {
+			params, _ := url.ParseQuery(string(body))
+			postData.Params = harValues(params)
+		}
+		if isText(contentType, body) {
+			postData.Text = string(body)
+		} else {
+			postData.Comment = "binary body, not recorded"
+		}
+		entry.Request.PostData = postData
+	}
+	entry.Timings, entry.Time = tracer.harTimings(started, ended)
+	tracer.mu.Lock()
+	if tracer.gotConn.Conn != nil {
+		if host, _, err := net.SplitHostPort(tracer.gotConn.Conn.RemoteAddr().String()); err == nil {
+			entry.ServerIPAddress = host
+		}
+	}
+	tracer.mu.Unlock()
+	return entry
+}
+
+// response returns the HAR response of resp, with its body.
+func (t *harTransport) response(resp *http.Response, body []byte) HARResponse {
+	contentType := resp.Header.Get("Content-Type")
+	body = t.policy.redactBody(contentType, body)
+	content := HARContent{Size: len(body), MimeType: contentType}
+	if isText(contentType, body) {
+		content.Text = string(body)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(body)
+		content.Encoding = "base64"
+	}
+	redirectURL := ""
+	if location, err := resp.Location(); err == nil {
+		redirectURL = t.policy.redactURL(location).String()
+	}
+	return HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  http.StatusText(resp.StatusCode),
+		HTTPVersion: resp.Proto,
+		Cookies:     t.cookies(resp.Cookies(), "Set-Cookie"),
+		Headers:     harHeaders(t.policy.redactHeader(resp.Header)),
+		Content:     content,
+		RedirectURL: redirectURL,
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+}
+
+// cookies returns the HAR cookies, with their values redacted if header is.
+func (t *harTransport) cookies(cookies []*http.Cookie, header string) []HARCookie {
+	redacted := containsFold(t.policy.Headers, header)
+	harCookies := make([]HARCookie, 0, len(cookies))
+	for _, cookie := range cookies {
+		c := HARCookie{Name: cookie.Name, Value: cookie.Value, Path: cookie.Path, Domain: cookie.Domain,
+			HTTPOnly: cookie.HttpOnly, Secure: cookie.Secure}
+		if redacted {
+			c.Value = Redacted
+		}
+		if !cookie.Expires.IsZero() {
+			expires := cookie.Expires
+			c.Expires = &expires
+		}
+		harCookies = append(harCookies, c)
+	}
+	return harCookies
+}
+
+func harHeaders(header http.Header) []HARNameValue {
+	values := []HARNameValue{}
+	for _, name := range sortedKeys(header) {
+		for _, value := range header[name] {
+			values = append(values, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return values
+}
+
+func harValues(query url.Values) []HARNameValue {
+	return harHeaders(http.Header(query))
+}
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// harTimings returns the timings of the exchange ended at ended, and their total.
+func (t *requestTracer) harTimings(started, ended time.Time) (HARTimings, float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ms := func(from, to time.Time) float64 {
+		if from.IsZero() || to.IsZero() {
+			return -1
+		}
+		return float64(to.Sub(from).Microseconds()) / 1000
+	}
+	timings := HARTimings{
+		DNS:     ms(t.dnsStart, t.dnsDone),
+		Connect: ms(t.connectStart, t.connectDone),
+		SSL:     ms(t.tlsStart, t.tlsDone),
+		Send:    ms(t.gotConnAt, t.wroteRequest),
+		Wait:    ms(t.wroteRequest, t.firstResponseByte),
+		Receive: ms(t.firstResponseByte, ended),
+	}
+	// HAR counts the TLS handshake in connect
+	if timings.Connect >= 0 && timings.SSL >= 0 {
+		timings.Connect += timings.SSL
+	}
+	timings.Blocked = ms(started, t.gotConnAt)
+	if timings.Blocked >= 0 {
+		timings.Blocked -= positive(timings.DNS) + positive(timings.Connect)
+		if timings.Blocked < 0 {
+			timings.Blocked = 0
+		}
+	}
+	for _, step := range []*float64{&timings.Send, &timings.Wait, &timings.Receive} {
+		if *step < 0 {
+			*step = 0
+		}
+	}
+	total := positive(timings.Blocked) + positive(timings.DNS) + positive(timings.Connect) +
+		timings.Send + timings.Wait + timings.Receive
+	return timings, total
+}
+
+func positive(f float64) float64 {
+	if f < 0 {
+		return 0
+	}
+	return f
+}
+
+// A captureBody keeps what is read from a body, and calls onClose once closed.
+// A request body may still be read by the transport while its bytes are recorded.
+type captureBody struct {
+	io.ReadCloser
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	once    sync.Once
+	onClose func()
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	b.buf.Write(p[:n])
+	b.mu.Unlock()
+	return n, err
+}
+
+// bytes returns a copy of what was read so far.
+func (b *captureBody) bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]byte(nil), b.buf.Bytes()...)
+}
+
+func (b *captureBody) Close() error {
+	err := b.ReadCloser.Close()
+	if b.onClose != nil {
+		b.once.Do(b.onClose)
+	}
+	return err
+}
@@ -0,0 +1,105 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRecordHAR(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new", http.StatusFound)
+		case "/new":
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret", HttpOnly: true})
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"ok":true,"token":"secret"}`))
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte{0x89, 'P', 'N', 'G'})
+		}
+	}))
+	defer ts.Close()
+
+	recorder := NewHARRecorder()
+	client := NewClient(ts.URL).RecordHAR(recorder)
+	client.Post("/old?page=1").
+		AddCookie(&http.Cookie{Name: "id", Value: "secret"}).
+		Retry(1, 0, http.StatusServiceUnavailable).
+		Send(`{"user":"me","password":"secret"}`).
+		End()
+	client.Get("/image").End()
+
+	har := recorder.HAR()
+	entries := har.Log.Entries
+	if har.Log.Version != "1.2" || len(entries) != 5 {
+		t.Fatal(fmt.Sprintf("Expected 5 entries: redirect and 503, redirect and 200, image | but got %d", len(entries)))
+	}
+	expected := []struct {
+		method, path string
+		status       int
+	}{
+		{"POST", "/old?page=1", 302},
+		{"GET", "/new", 503},
+		{"POST", "/old?page=1", 302},
+		{"GET", "/new", 200},
+		{"GET", "/image", 200},
+	}
+	for i, entry := range entries {
+		if entry.Request.Method != expected[i].method || entry.Request.URL != ts.URL+expected[i].path ||
+			entry.Response.Status != expected[i].status {
+			t.Error(fmt.Sprintf("Expected %+v | but got %s %s %d", expected[i], entry.Request.Method, entry.Request.URL, entry.Response.Status))
+		}
+		if entry.ServerIPAddress != "127.0.0.1" || entry.Time <= 0 || entry.Timings.Wait < 0 || entry.Timings.Send < 0 {
+			t.Error(fmt.Sprintf("Expected timings | but got %v %+v", entry.Time, entry.Timings))
+		}
+	}
+
+	first := entries[0]
+	if first.Request.PostData == nil || first.Request.PostData.Text != `{"password":"[REDACTED]","user":"me"}` {
+		t.Error(fmt.Sprintf("Expected redacted post data | but got %+v", first.Request.PostData))
+	}
+	if len(first.Request.Cookies) != 1 || first.Request.Cookies[0].Name != "id" || first.Request.Cookies[0].Value != Redacted {
+		t.Error(fmt.Sprintf("Expected redacted request cookie | but got %+v", first.Request.Cookies))
+	}
+	if first.Response.RedirectURL != ts.URL+"/new" || len(first.Request.QueryString) != 1 || first.Request.QueryString[0].Value != "1" {
+		t.Error(fmt.Sprintf("Expected redirect url and query string | but got %+v", first))
+	}
+	ok := entries[3].Response
+	if ok.Content.Text != `{"ok":true,"token":"[REDACTED]"}` || ok.Content.MimeType != "application/json" ||
+		len(ok.Cookies) != 1 || ok.Cookies[0].Value != Redacted || !ok.Cookies[0].HTTPOnly {
+		t.Error(fmt.Sprintf("Expected redacted response | but got %+v", ok))
+	}
+	if image := entries[4].Response.Content; image.Encoding != "base64" || image.Text != "iVBORw==" || image.Size != 4 {
+		t.Error(fmt.Sprintf("Expected base64 binary content | but got %+v", image))
+	}
+
+	// the archive is valid JSON, also for failed exchanges
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	New().RecordHAR(recorder).Get(closed.URL).End()
+	filename := filepath.Join(t.TempDir(), "test.har")
+	if err := recorder.WriteFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filename)
+	var decoded HAR
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.Log.Entries) != 6 || decoded.Log.Entries[5].Response.Error == "" {
+		t.Error(fmt.Sprintf("Expected failed exchange in archive | but got %v", err))
+	}
+	if !bytes.Contains(data, []byte(`"startedDateTime": "`)) {
+		t.Error("Expected startedDateTime in archive")
+	}
+}
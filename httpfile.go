@@ -0,0 +1,529 @@
+package gorequest
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// An HTTPFile holds the requests of a file in the .http format of editor REST clients:
+//
+//	@host = https://api.example.com
+//
+//	### Create a user
+//	# @name createUser
+//	POST {{host}}/users
+//	Content-Type: application/json
+//	Authorization: Bearer {{token}}
+//
+//	{"name": "gopher"}
+//
+//	### Get the user
+//	GET {{host}}/users/{{createUser.response.body.$.id}}
+//
+// Requests are separated by lines starting with "###". Lines starting with "#" or "//"
+// are comments, "@name = value" lines define variables and "{{name}}" is replaced by the
+// value of a variable. Besides the variables of the file and of the environment, named
+// requests expose their request and response, as name.request.headers.Name,
+// name.response.body.* for the whole body, or name.response.body.$.path for a value of
+// a JSON body. The system variables {{$guid}}, {{$timestamp}}, {{$randomInt min max}}
+// and {{$processEnv NAME}} are supported too. A "< file" body sends the content of a
+// file, "<@ file" after replacing its variables.
+type HTTPFile struct {
+	// Variables are the variables defined by the file.
+	Variables map[string]string
+	Requests  []HTTPFileRequest
+	// dir is the directory of the file, which included files are relative to.
+	dir string
+}
+
+// An HTTPFileRequest is a request of an HTTPFile, before its variables are replaced.
+type HTTPFileRequest struct {
+	// Name is set by a "# @name" comment, for the next requests to use the request and its response.
+	Name   string
+	Method string
+	URL    string
+	Header http.Header
+	Body   string
+	// BodyFile is the file sent as the body by a "< file" line, BodyFileVariables
+	// tells whether its variables are replaced.
+	BodyFile          string
+	BodyFileVariables bool
+	// Line is the line of the request in the file.
+	Line int
+}
+
+// An HTTPFileResult is the outcome of an HTTPFileRequest, see HTTPFile.Run.
+type HTTPFileResult struct {
+	Request *HTTPFileRequest
+	// Method, URL, Header and Body are sent, with the variables replaced.
+	Method   string
+	URL      string
+	Header   http.Header
+	Body     string
+	Response Response
+	// ResponseBody is the body of Response.
+	ResponseBody []byte
+	Errors       []error
+}
+
+var (
+	httpFileMethods  = regexp.MustCompile(`^(GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS|CONNECT|TRACE)\s+`)
+	httpFileVariable = regexp.MustCompile(`^@([A-Za-z_][\w.-]*)\s*=\s*(.*)$`)
+	httpFileName     = regexp.MustCompile(`^(#|//)\s*@name\s+(\S+)`)
+	httpFileVersion  = regexp.MustCompile(`\s+HTTP/\d(\.\d)?$`)
+	httpFileRef      = regexp.MustCompile(`\{\{\s*([^{}]*?)\s*\}\}`)
+)
+
+// LoadHTTPFile parses the .http file filename, see ParseHTTPFile.
+func LoadHTTPFile(filename string) (*HTTPFile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	file, err := ParseHTTPFile(f)
+	if err != nil {
+		return nil, errors.Wrap(err, filename)
+	}
+	file.dir = filepath.Dir(filename)
+	return file, nil
+}
+
+// ParseHTTPFile parses the requests of a .http file, see HTTPFile. The files included by
+// "< file" bodies are relative to the current directory, use LoadHTTPFile for them to be
+// relative to the .http file.
+func ParseHTTPFile(r io.Reader) (*HTTPFile, error) {
+	file := &HTTPFile{Variables: map[string]string{}}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	var block []string
+	start, n := 1, 0
+	for scanner.Scan() {
+		n++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(line, "###") {
+			if err := file.parseBlock(block, start); err != nil {
+				return nil, err
+			}
+			block, start = nil, n+1
+			continue
+		}
+		block = append(block, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := file.parseBlock(block, start); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// parseBlock parses the lines between two "###" separators, starting at line start.
+func (f *HTTPFile) parseBlock(lines []string, start int) error {
+	req := HTTPFileRequest{Header: http.Header{}}
+	i := 0
+	// comments and variables before the request line
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if m := httpFileName.FindStringSubmatch(line); m != nil {
+			req.Name = m[2]
+		} else if m := httpFileVariable.FindStringSubmatch(line); m != nil {
+			f.Variables[m[1]] = strings.TrimSpace(m[2])
+		} else if line != "" && !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "//") {
+			break
+		}
+	}
+	if i == len(lines) {
+		return nil
+	}
+
+	req.Line = start + i
+	line := strings.TrimSpace(lines[i])
+	if m := httpFileMethods.FindStringSubmatch(line); m != nil {
+		req.Method, req.URL = m[1], strings.TrimSpace(line[len(m[0]):])
+	} else {
+		req.Method, req.URL = GET, line
+	}
+	// query parameters continued on the next lines
+	for i++; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "?") && !strings.HasPrefix(line, "&") {
+			break
+		}
+		req.URL += line
+	}
+	req.URL = httpFileVersion.ReplaceAllString(req.URL, "")
+
+	for ; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if line == "" {
+			i++
+			break
+		}
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		colon := strings.IndexByte(line, ':')
+		if colon <= 0 {
+			return errors.Errorf("gorequest: invalid header %q at line %d", line, start+i)
+		}
+		req.Header.Add(strings.TrimSpace(line[:colon]), strings.TrimSpace(line[colon+1:]))
+	}
+
+	body := lines[i:]
+	for len(body) != 0 && strings.TrimSpace(body[len(body)-1]) == "" {
+		body = body[:len(body)-1]
+	}
+	if len(body) != 0 && (strings.HasPrefix(body[0], "< ") || strings.HasPrefix(body[0], "<@ ")) {
+		req.BodyFileVariables = body[0][1] == '@'
+		req.BodyFile = strings.TrimSpace(strings.TrimPrefix(body[0][1:], "@"))
+	} else if mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mediaType == "application/x-www-form-urlencoded" {
+		// form fields may be written one per line
+		for j := range body {
+			body[j] = strings.TrimSpace(body[j])
+		}
+		req.Body = strings.Join(body, "")
+	} else {
+		req.Body = strings.Join(body, "\n")
+	}
+	f.Requests = append(f.Requests, req)
+	return nil
+}
+
+// LoadHTTPEnvironment returns the variables of the environment env in the JSON files
+// filenames, in the format of http-client.env.json files:
+//
+//	{
+//	  "$shared": {"version": "v2"},
+//	  "dev": {"host": "http://localhost:8080", "token": "dev-token"},
+//	  "prod": {"host": "https://api.example.com"}
+//	}
+//
+// The variables of "$shared" apply to every environment. The files are merged in order,
+// typically http-client.env.json then http-client.private.env.json.
+func LoadHTTPEnvironment(env string, filenames ...string) (map[string]string, error) {
+	variables := map[string]string{}
+	found := false
+	for _, filename := range filenames {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		var envs map[string]map[string]interface{}
+		if err := json.Unmarshal(data, &envs); err != nil {
+			return nil, errors.Wrapf(err, "gorequest: invalid environment file %s", filename)
+		}
+		for _, name := range []string{"$shared", env} {
+			values, ok := envs[name]
+			found = found || (ok && name == env)
+			for key, value := range values {
+				if s, ok := value.(string); ok {
+					variables[key] = s
+				} else {
+					data, _ := json.Marshal(value)
+					variables[key] = string(data)
+				}
+			}
+		}
+	}
+	if !found {
+		return nil, errors.Errorf("gorequest: no environment %q in %s", env, strings.Join(filenames, ", "))
+	}
+	return variables, nil
+}
+
+// Run sends the requests of the file in order, each started from client, or from a new
+// Client if client is nil, so that they share its defaults and relative urls are resolved
+// against its base url. The variables of the file override the ones of env.
+//
+// Run returns the results of the requests sent, and an error if a variable can't be
+// replaced, which stops the run. The requests failing, or whose status isn't 2xx, don't:
+//
+//	file, err := gorequest.LoadHTTPFile("testdata/smoke.http")
+//	env, err := gorequest.LoadHTTPEnvironment("dev", "testdata/http-client.env.json")
+//	results, err := file.Run(nil, env)
+//	for _, result := range results {
+//	  if result.Errors != nil || result.Response.StatusCode >= 400 {
+//	    t.Errorf("%s %s: %v %v", result.Method, result.URL, result.Response, result.Errors)
+//	  }
+//	}
+func (f *HTTPFile) Run(client *Client, env map[string]string) ([]HTTPFileResult, error) {
+	if client == nil {
+		client = NewClient("")
+	}
+	run := &httpFileRun{file: f, env: env, named: map[string]*HTTPFileResult{}}
+	results := make([]HTTPFileResult, 0, len(f.Requests))
+	for i := range f.Requests {
+		req := &f.Requests[i]
+		result, err := run.prepare(req)
+		if err != nil {
+			return results, errors.Wrapf(err, "gorequest: request at line %d", req.Line)
+		}
+
+		s := client.CustomMethod(result.Method, result.URL)
+		for name, values := range result.Header {
+			for _, value := range values {
+				if name == "Authorization" && basicAuthPlain(value) {
+					credentials := strings.TrimSpace(value[len("Basic "):])
+					username, password := credentials, ""
+					if sep := strings.IndexAny(credentials, ": "); sep >= 0 {
+						username, password = credentials[:sep], strings.TrimSpace(credentials[sep+1:])
+					}
+					s.SetBasicAuth(username, password)
+					continue
+				}
+				s.AppendHeader(name, value)
+			}
+		}
+		if result.Body != "" {
+			s.Type(TypeText).SendString(result.Body)
+		}
+		result.Response, result.ResponseBody, result.Errors = s.EndBytes()
+		results = append(results, *result)
+		if req.Name != "" {
+			run.named[req.Name] = result
+		}
+	}
+	return results, nil
+}
+
+// basicAuthPlain tells whether an Authorization header has plain basic credentials,
+// "Basic user:password" or "Basic user password", which are encoded like REST clients do.
+func basicAuthPlain(value string) bool {
+	if !strings.HasPrefix(value, "Basic ") {
+		return false
+	}
+	credentials := strings.TrimSpace(value[len("Basic "):])
+	if strings.ContainsAny(credentials, ": ") {
+		return true
+	}
+	_, err := base64.StdEncoding.DecodeString(credentials)
+	return err != nil
+}
+
+// An httpFileRun replaces the variables of the requests of a file.
+type httpFileRun struct {
+	file  *HTTPFile
+	env   map[string]string
+	named map[string]*HTTPFileResult
+}
+
+// prepare returns the result of req, with its variables replaced.
+func (r *httpFileRun) prepare(req *HTTPFileRequest) (*HTTPFileResult, error) {
+	result := &HTTPFileResult{Request: req, Method: req.Method, Header: http.Header{}}
+	var err error
+	if result.URL, err = r.replace(req.URL, 0); err != nil {
+		return nil, err
+	}
+	for name, values := range req.Header {
+		for _, value := range values {
+			if value, err = r.replace(value, 0); err != nil {
+				return nil, err
+			}
+			result.Header.Add(name, value)
+		}
+	}
+	if req.BodyFile != "" {
+		filename := req.BodyFile
+		if !filepath.IsAbs(filename) {
+			filename = filepath.Join(r.file.dir, filename)
+		}
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		result.Body = string(data)
+		if !req.BodyFileVariables {
+			return result, nil
+		}
+	} else {
+		result.Body = req.Body
+	}
+	if result.Body, err = r.replace(result.Body, 0); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// replace replaces the variables of s. depth counts the nested replacements, to detect cycles.
+func (r *httpFileRun) replace(s string, depth int) (string, error) {
+	if depth > 10 {
+		return "", errors.Errorf("variables nested too deeply in %q", s)
+	}
+	var err error
+	replaced := httpFileRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		var value string
+		value, err = r.variable(httpFileRef.FindStringSubmatch(ref)[1], depth)
+		return value
+	})
+	return replaced, err
+}
+
+// variable returns the value of the variable name.
+func (r *httpFileRun) variable(name string, depth int) (string, error) {
+	if strings.HasPrefix(name, "$") {
+		return systemVariable(name)
+	}
+	if value, ok := r.file.Variables[name]; ok {
+		return r.replace(value, depth+1)
+	}
+	if value, ok := r.env[name]; ok {
+		return r.replace(value, depth+1)
+	}
+	if parts := strings.SplitN(name, ".", 4); len(parts) == 4 {
+		if result, ok := r.named[parts[0]]; ok {
+			return result.variable(parts[1], parts[2], parts[3])
+		}
+		for _, req := range r.file.Requests {
+			if req.Name == parts[0] {
+				return "", errors.Errorf("request %s wasn't sent before %q", parts[0], name)
+			}
+		}
+	}
+	return "", errors.Errorf("unknown variable %q", name)
+}
+
+// variable returns the header or body value of the request or response of the result.
+func (result *HTTPFileResult) variable(entity, part, path string) (string, error) {
+	var (
+		header http.Header
+		body   []byte
+	)
+	switch entity {
+	case "request":
+		header, body = result.Header, []byte(result.Body)
+	case "response":
+		if result.Response == nil {
+			return "", errors.Errorf("request %s has no response: %v", result.Request.Name, result.Errors)
+		}
+		header, body = result.Response.Header, result.ResponseBody
+	default:
+		return "", errors.Errorf("unknown entity %q of request %s, expected request or response", entity, result.Request.Name)
+	}
+	switch part {
+	case "headers":
+		values, ok := header[http.CanonicalHeaderKey(path)]
+		if !ok {
+			return "", errors.Errorf("no header %s in the %s of %s", path, entity, result.Request.Name)
+		}
+		return strings.Join(values, ", "), nil
+	case "body":
+		if path == "*" {
+			return string(body), nil
+		}
+		return jsonPathValue(body, path)
+	}
+	return "", errors.Errorf("unknown part %q of request %s, expected headers or body", part, result.Request.Name)
+}
+
+// jsonPathValue returns the value at path in a JSON document, path being "$" followed
+// by ".key" and "[index]" selectors. Strings are returned as they are, other values as JSON.
+func jsonPathValue(body []byte, path string) (string, error) {
+	if !strings.HasPrefix(path, "$") {
+		return "", errors.Errorf("invalid JSON path %q, expected $ or *", path)
+	}
+	decoder := json.NewDecoder(strings.NewReader(string(body)))
+	decoder.UseNumber()
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		return "", errors.Wrap(err, "body isn't JSON")
+	}
+	rest := path[1:]
+	for rest != "" {
+		var key string
+		switch {
+		case rest[0] == '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key, rest = rest[1:1+end], rest[1+end:]
+		case rest[0] == '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return "", errors.Errorf("invalid JSON path %q", path)
+			}
+			key, rest = strings.Trim(rest[1:end], `'"`), rest[end+1:]
+		default:
+			return "", errors.Errorf("invalid JSON path %q", path)
+		}
+		switch value := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = value[key]; !ok {
+				return "", errors.Errorf("no %s in JSON body", path)
+			}
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(value) {
+				return "", errors.Errorf("no %s in JSON body", path)
+			}
+			v = value[index]
+		default:
+			return "", errors.Errorf("no %s in JSON body", path)
+		}
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+// systemVariable returns the value of a system variable, such as $guid.
+func systemVariable(name string) (string, error) {
+	args := strings.Fields(name)
+	switch args[0] {
+	case "$guid", "$uuid":
+		var b [16]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return "", err
+		}
+		b[6] = b[6]&0x0f | 0x40
+		b[8] = b[8]&0x3f | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+	case "$timestamp":
+		return strconv.FormatInt(time.Now().Unix(), 10), nil
+	case "$randomInt":
+		min, max := int64(0), int64(1000)
+		if len(args) == 3 {
+			var err1, err2 error
+			min, err1 = strconv.ParseInt(args[1], 10, 64)
+			max, err2 = strconv.ParseInt(args[2], 10, 64)
+			if err1 != nil || err2 != nil || max <= min {
+				return "", errors.Errorf("invalid %q, expected $randomInt min max", name)
+			}
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(max-min))
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatInt(min+n.Int64(), 10), nil
+	case "$processEnv":
+		if len(args) != 2 {
+			return "", errors.Errorf("invalid %q, expected $processEnv NAME", name)
+		}
+		return os.Getenv(args[1]), nil
+	}
+	return "", errors.Errorf("unknown system variable %q", name)
+}
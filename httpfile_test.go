@@ -0,0 +1,168 @@
+package gorequest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHTTPFile(t *testing.T) {
+	file, err := ParseHTTPFile(strings.NewReader(`@host = http://localhost
+// a comment
+
+### first
+# @name search
+GET {{host}}/search
+  ?q=gopher
+  &page=2 HTTP/1.1
+Accept: application/json
+# X-Disabled: true
+
+###
+POST {{host}}/form
+Content-Type: application/x-www-form-urlencoded
+
+a=1
+&b=2
+
+### xml
+PUT {{host}}/xml
+Content-Type: application/xml
+
+<user>
+  <name>gopher</name>
+</user>
+
+
+###
+{{host}}/upload
+Content-Type: application/json
+
+<@ ./body.json
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Requests) != 4 || file.Variables["host"] != "http://localhost" {
+		t.Fatal(fmt.Sprintf("Expected 4 requests and the host variable | but got %+v", file))
+	}
+	search := file.Requests[0]
+	if search.Name != "search" || search.Method != GET || search.URL != "{{host}}/search?q=gopher&page=2" || search.Line != 6 ||
+		!reflect.DeepEqual(search.Header, http.Header{"Accept": {"application/json"}}) || search.Body != "" {
+		t.Error(fmt.Sprintf("Expected the search request | but got %+v", search))
+	}
+	if form := file.Requests[1]; form.Method != POST || form.Body != "a=1&b=2" {
+		t.Error(fmt.Sprintf("Expected the form fields to be joined | but got %+v", form))
+	}
+	if xml := file.Requests[2]; xml.Body != "<user>\n  <name>gopher</name>\n</user>" {
+		t.Error(fmt.Sprintf("Expected the xml body | but got %q", xml.Body))
+	}
+	if upload := file.Requests[3]; upload.Method != GET || upload.BodyFile != "./body.json" || !upload.BodyFileVariables {
+		t.Error(fmt.Sprintf("Expected the included body | but got %+v", upload))
+	}
+
+	if _, err := ParseHTTPFile(strings.NewReader("GET /\nnot a header\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Error(fmt.Sprintf("Expected an invalid header error | but got %v", err))
+	}
+}
+
+func TestHTTPFileRun(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		switch r.URL.Path {
+		case "/v2/users":
+			w.Header().Set("Location", "/v2/users/7")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"user": {"id": 7, "tags": ["a", "b"]}, "echo": %s}`, body)
+		default:
+			username, password, _ := r.BasicAuth()
+			fmt.Fprintf(w, "%s %s %s:%s %s %s", r.Method, r.URL.RequestURI(), username, password, r.Header.Get("X-Version"), body)
+		}
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	ioutil.WriteFile(filepath.Join(dir, "user.json"), []byte(`{"name": "{{name}}"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "http-client.env.json"), []byte(`{
+  "$shared": {"version": "v2"},
+  "dev": {"name": "dev gopher", "password": "dev"},
+  "prod": {"name": "prod gopher"}
+}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "http-client.private.env.json"), []byte(`{"dev": {"password": "secret"}}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "smoke.http"), []byte(`@path = /{{version}}/users
+
+### create
+# @name create
+POST {{path}}
+Content-Type: application/json
+
+<@ user.json
+
+### read
+GET {{create.response.headers.Location}}?tag={{create.response.body.$.user.tags[1]}}
+Authorization: Basic admin:{{password}}
+X-Version: {{create.response.body.$.user.id}}
+
+{{create.request.body.*}}
+`), 0644)
+
+	file, err := LoadHTTPFile(filepath.Join(dir, "smoke.http"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := LoadHTTPEnvironment("dev", filepath.Join(dir, "http-client.env.json"), filepath.Join(dir, "http-client.private.env.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := file.Run(NewClient(ts.URL), env)
+	if err != nil || len(results) != 2 {
+		t.Fatal(fmt.Sprintf("Expected 2 results | but got %v, %v", results, err))
+	}
+	if create := results[0]; create.Errors != nil || create.Response.StatusCode != http.StatusCreated ||
+		create.URL != "/v2/users" || create.Body != `{"name": "dev gopher"}` {
+		t.Error(fmt.Sprintf("Expected the user to be created | but got %+v", create))
+	}
+	expected := `GET /v2/users/7?tag=b admin:secret 7 {"name": "dev gopher"}`
+	if read := results[1]; read.Errors != nil || string(read.ResponseBody) != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s, %v", expected, read.ResponseBody, read.Errors))
+	}
+
+	if _, err := LoadHTTPEnvironment("staging", filepath.Join(dir, "http-client.env.json")); err == nil {
+		t.Error("Expected an unknown environment error | but got none")
+	}
+}
+
+func TestHTTPFileRunErrors(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.URL.Path)
+	}))
+	defer ts.Close()
+
+	for source, message := range map[string]string{
+		"GET /a\n###\nGET /{{missing}}":                              "request at line 3: unknown variable \"missing\"",
+		"GET /{{later.response.body.*}}\n###\n# @name later\nGET /b": "request later wasn't sent before",
+		"# @name a\nGET /a\n###\nGET /{{a.response.body.$.id}}":      "body isn't JSON",
+		"@a = {{b}}\n@b = {{a}}\nGET /{{a}}":                         "nested too deeply",
+		"GET /{{$randomInt 5 1}}":                                    "expected $randomInt min max",
+	} {
+		file, err := ParseHTTPFile(strings.NewReader(source))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = file.Run(NewClient(ts.URL), nil)
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Error(fmt.Sprintf("Expected an error containing %s | but got %v", message, err))
+		}
+	}
+
+	file, _ := ParseHTTPFile(strings.NewReader("GET /{{$guid}}/{{$randomInt 1 2}}"))
+	results, err := file.Run(NewClient(ts.URL), nil)
+	if err != nil || len(results[0].URL) != len("/xxxxxxxx-xxxx-4xxx-xxxx-xxxxxxxxxxxx/1") || !strings.HasSuffix(results[0].URL, "/1") {
+		t.Error(fmt.Sprintf("Expected system variables | but got %v, %v", results, err))
+	}
+}
@@ -1,7 +1,126 @@
 package gorequest
 
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
 type Logger interface {
 	SetPrefix(string)
 	Printf(format string, v ...interface{})
 	Println(v ...interface{})
 }
+
+// SetStructuredLogger makes the SuperAgent log with logger instead of the Logger set by
+// SetLogger. Every record holds the method and url of the request, and:
+//
+//   - a request which got a response logs "request finished" at info level, with its
+//     status, duration and attempt;
+//   - a retry logs "retrying request" at warn level;
+//   - a request which got no response logs "request failed" at error level;
+//   - in debug mode, the dumps of requests and responses, the curl command and the trace
+//     are logged at debug level.
+//
+// The level is filtered by the handler of logger:
+//
+//	handler := slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})
+//	gorequest.New().SetStructuredLogger(slog.New(handler))
+//
+// Records are logged with the context set by Context, if any.
+func (s *SuperAgent) SetStructuredLogger(logger *slog.Logger) *SuperAgent {
+	s.slogger = logger
+	return s
+}
+
+// SetStructuredLogger returns a Client logging with logger, see SuperAgent.SetStructuredLogger.
+func (c *Client) SetStructuredLogger(logger *slog.Logger) *Client {
+	return c.with(func(s *SuperAgent) { s.SetStructuredLogger(logger) })
+}
+
+// LogFields adds fields to the records of the current request, see SetStructuredLogger.
+// args are key-value pairs or slog.Attr, as for slog.Logger.With:
+//
+//	request.Get(url).LogFields("user_id", 42, "job", "sync").End()
+func (s *SuperAgent) LogFields(args ...interface{}) *SuperAgent {
+	s.logFields = append(s.logFields, args...)
+	return s
+}
+
+// log logs a record with the structured logger, adding the request fields.
+func (s *SuperAgent) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	ctx := s.context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := s.slogger
+	if len(s.logFields) != 0 {
+		logger = logger.With(s.logFields...)
+	}
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.LogAttrs(ctx, level, msg, attrs...)
+}
+
+// logDebug logs a debug message with the structured logger if set, or the Logger.
+// prefix tags the message for the Logger, which isn't modified as clones share it.
+func (s *SuperAgent) logDebug(prefix, msg string, key string, value interface{}, err error) {
+	if s.slogger != nil {
+		if err != nil {
+			s.log(slog.LevelDebug, msg, slog.String("method", s.Method), slog.Any("error", err))
+		} else {
+			s.log(slog.LevelDebug, msg, slog.String("method", s.Method), slog.Any(key, value))
+		}
+		return
+	}
+	if err != nil {
+		s.logger.Println(prefix+"Error:", err)
+	} else {
+		s.logger.Printf("%s%s: %s", prefix, msg, value)
+	}
+}
+
+// logResult logs the outcome of an attempt with the structured logger, if set.
+func (s *SuperAgent) logResult(resp Response, errs []error, start time.Time, retrying bool) {
+	if s.slogger == nil {
+		return
+	}
+	attempt := s.Retryable.Attempt + 1
+	if retrying {
+		// isRetryableRequest already counted the retry
+		attempt--
+	}
+	url := s.redaction().redactRawURL(resolveURL(s.baseURL, s.Url))
+	if resp != nil && resp.Request != nil {
+		url = s.redaction().redactURL(resp.Request.URL).String()
+	}
+	attrs := []slog.Attr{
+		slog.String("method", s.Method),
+		slog.String("url", url),
+		slog.Int("attempt", attempt),
+		slog.Duration("duration", time.Since(start)),
+	}
+	switch {
+	case len(errs) != 0:
+		s.log(slog.LevelError, "request failed", append(attrs, slog.Any("error", s.redaction().redactError(errs[0])))...)
+	case retrying:
+		s.log(slog.LevelWarn, "retrying request", append(attrs, slog.Int("status", resp.StatusCode))...)
+	default:
+		s.log(slog.LevelInfo, "request finished", append(attrs, slog.Int("status", resp.StatusCode))...)
+	}
+}
+
+// traceAttrs returns the timings of info as a slog group.
+func traceAttrs(info TraceInfo) slog.Attr {
+	return slog.Group("trace",
+		slog.Duration("dns", info.DNSLookup),
+		slog.Duration("connect", info.Connect),
+		slog.Duration("tls", info.TLSHandshake),
+		slog.Duration("server", info.ServerProcessing),
+		slog.Duration("ttfb", info.TimeToFirstByte),
+		slog.Duration("total", info.Total),
+		slog.String("remote_addr", info.RemoteAddr),
+		slog.Bool("reused", info.ConnReused),
+	)
+}
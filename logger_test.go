@@ -0,0 +1,110 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestStructuredLogger(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelInfo}))
+	request := New().SetStructuredLogger(logger)
+	request.Get(ts.URL+"/users").LogFields("job", "sync").Retry(1, 0, http.StatusServiceUnavailable).End()
+
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatal(fmt.Sprintf("Expected a retry and a result record | but got %q", buf.String()))
+	}
+	expected := []struct {
+		level, msg      string
+		status, attempt float64
+	}{
+		{"WARN", "retrying request", 503, 1},
+		{"INFO", "request finished", 200, 2},
+	}
+	for i, record := range records {
+		if record["level"] != expected[i].level || record["msg"] != expected[i].msg ||
+			record["status"] != expected[i].status || record["attempt"] != expected[i].attempt ||
+			record["method"] != "GET" || record["url"] != ts.URL+"/users" || record["job"] != "sync" {
+			t.Error(fmt.Sprintf("Expected %+v | but got %v", expected[i], record))
+		}
+	}
+
+	// fields are per request, debug records need the debug level
+	buf.Reset()
+	request.SetDebug(true).Get(ts.URL).End()
+	if strings.Contains(buf.String(), "job") || strings.Contains(buf.String(), "HTTP Request") {
+		t.Error(fmt.Sprintf("Expected no request fields nor debug records | but got %q", buf.String()))
+	}
+
+	buf.Reset()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	debugLogger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	New().SetStructuredLogger(debugLogger).SetDebug(true).Get(closed.URL).End()
+	out := buf.String()
+	if !strings.Contains(out, `level=DEBUG msg="HTTP Request" method=GET dump=`) || !strings.Contains(out, `level=ERROR msg="request failed"`) {
+		t.Error(fmt.Sprintf("Expected debug dump and error | but got %q", out))
+	}
+
+	// failed requests are logged without secrets too
+	buf.Reset()
+	secretURL := strings.Replace(closed.URL, "http://", "http://gopher:hunter2@", 1) + "/users?token=s3cr3t&page=2"
+	New().SetStructuredLogger(debugLogger).Get(secretURL).End()
+	out = buf.String()
+	if !strings.Contains(out, `msg="request failed"`) || !strings.Contains(out, "page=2") ||
+		strings.Contains(out, "hunter2") || strings.Contains(out, "s3cr3t") {
+		t.Error(fmt.Sprintf("Expected a redacted failed request | but got %q", out))
+	}
+}
+
+// A prefixLogger counts the calls to SetPrefix.
+type prefixLogger struct {
+	*log.Logger
+	prefixes int32
+}
+
+func (l *prefixLogger) SetPrefix(prefix string) {
+	atomic.AddInt32(&l.prefixes, 1)
+	l.Logger.SetPrefix(prefix)
+}
+
+func TestDebugDoesNotSetPrefix(t *testing.T) {
+	ts := httptest.NewServer(http.NotFoundHandler())
+	defer ts.Close()
+
+	var buf bytes.Buffer
+	logger := &prefixLogger{Logger: log.New(&buf, "[gorequest]", 0)}
+	base := New().SetLogger(logger).SetDebug(true).SetCurlCommand(true)
+	base.Clone().Get(ts.URL).End()
+	base.Clone().Get(ts.URL).End()
+	if logger.prefixes != 0 {
+		t.Error("Expected debug mode not to change the prefix of a shared logger")
+	}
+	if !strings.Contains(buf.String(), "[gorequest][http] HTTP Request: GET") || !strings.Contains(buf.String(), "[gorequest][curl] CURL command line: curl") {
+		t.Error(fmt.Sprintf("Expected tagged debug messages | but got %q", buf.String()))
+	}
+}
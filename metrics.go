@@ -0,0 +1,65 @@
+package gorequest
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// A Metrics records the requests of a SuperAgent, see SetMetrics.
+// Its methods are called concurrently by the SuperAgents sharing it.
+type Metrics interface {
+	// RequestStarted is called before the first attempt of a request.
+	// The StatusClass of labels is empty.
+	RequestStarted(labels MetricLabels)
+	// RequestRetried is called before each retry, with the StatusClass of the failed attempt.
+	RequestRetried(labels MetricLabels)
+	// RequestFinished is called once the request is done, retries included.
+	RequestFinished(labels MetricLabels, duration time.Duration)
+}
+
+// MetricLabels describe a request. They have a low cardinality: Route is the path
+// template set with PathParam, or empty, never the actual path.
+type MetricLabels struct {
+	Method string
+	Host   string
+	Route  string
+	// StatusClass is "1xx" to "5xx", or "error" if no response was received.
+	StatusClass string
+}
+
+// SetMetrics makes the SuperAgent record its requests in metrics, for instance the
+// collector of the gorequest/promtext package:
+//
+//	collector := promtext.NewCollector()
+//	http.Handle("/metrics", collector)
+//
+//	gorequest.New().SetMetrics(collector).Get("https://example.com/users/{id}").PathParam("id", 42).End()
+func (s *SuperAgent) SetMetrics(metrics Metrics) *SuperAgent {
+	s.metrics = metrics
+	return s
+}
+
+// SetMetrics returns a Client recording its requests, see SuperAgent.SetMetrics.
+func (c *Client) SetMetrics(metrics Metrics) *Client {
+	return c.with(func(s *SuperAgent) { s.SetMetrics(metrics) })
+}
+
+// metricLabels returns the labels of the request, without StatusClass.
+func (s *SuperAgent) metricLabels() MetricLabels {
+	labels := MetricLabels{Method: s.Method, Route: s.urlTemplate()}
+	if u, err := url.Parse(resolveURL(s.baseURL, s.Url)); err == nil {
+		labels.Host = u.Host
+	}
+	return labels
+}
+
+// withStatus returns labels with the StatusClass of resp.
+func (labels MetricLabels) withStatus(resp Response) MetricLabels {
+	if resp == nil {
+		labels.StatusClass = "error"
+	} else {
+		labels.StatusClass = strconv.Itoa(resp.StatusCode/100) + "xx"
+	}
+	return labels
+}
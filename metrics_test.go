@@ -0,0 +1,46 @@
+package gorequest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// A memoryMetrics records the calls of the Metrics interface.
+type memoryMetrics struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *memoryMetrics) record(call string, labels MetricLabels) {
+	m.mu.Lock()
+	m.calls = append(m.calls, fmt.Sprintf("%s %s %s %q %s", call, labels.Method, labels.Host, labels.Route, labels.StatusClass))
+	m.mu.Unlock()
+}
+
+func (m *memoryMetrics) RequestStarted(labels MetricLabels) { m.record("started", labels) }
+
+func (m *memoryMetrics) RequestRetried(labels MetricLabels) { m.record("retried", labels) }
+
+func (m *memoryMetrics) RequestFinished(labels MetricLabels, duration time.Duration) {
+	m.record("finished", labels)
+}
+
+func TestMetrics(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	host := closed.Listener.Addr().String()
+
+	metrics := &memoryMetrics{}
+	New().SetMetrics(metrics).Post(closed.URL + "/users/1?page=2").End()
+	expected := []string{
+		fmt.Sprintf(`started POST %s "" `, host),
+		fmt.Sprintf(`finished POST %s "" error`, host),
+	}
+	if fmt.Sprint(metrics.calls) != fmt.Sprint(expected) {
+		t.Error(fmt.Sprintf("Expected %q | but got %q", expected, metrics.calls))
+	}
+}
@@ -0,0 +1,386 @@
+package gorequest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+// PathParam sets the value of a {name} placeholder in the url. The value is escaped,
+// so it can't add or remove path segments:
+//
+//	gorequest.New().
+//	  Get("https://api.github.com/users/{user}/repos/{repo}").
+//	  PathParam("user", "parnurzeal").
+//	  PathParam("repo", "gorequest").
+//	  End()
+//
+// The url is a RFC 6570 URI template, so all its expressions are supported:
+//
+//	gorequest.New().
+//	  Get("https://example.com/search{?q,tags*}").
+//	  PathParam("q", "gopher").
+//	  PathParam("tags", []string{"go", "http"}).
+//	  End()
+//
+// This will GET https://example.com/search?q=gopher&tags=go&tags=http
+//
+// The value can be a string or any other primitive, a time.Time, a time.Duration or
+// an encoding.TextMarshaler, a slice of these, or a map with string keys.
+// A missing value for a {name} or {+name} expression is an error, other expressions
+// are left out when their value is missing, as RFC 6570 requires.
+// Templates are only expanded once PathParam or PathParams has been called, so that
+// urls holding literal braces, as in "?q={x}", are sent as they are.
+func (s *SuperAgent) PathParam(name string, value interface{}) *SuperAgent {
+	if s.pathParams == nil {
+		s.pathParams = make(map[string]interface{})
+	}
+	s.pathParams[name] = value
+	return s
+}
+
+// PathParams sets several path parameters at once (see PathParam). It accepts a map
+// with string keys or a struct with `path` tags (see BindStruct for the tag format):
+//
+//	gorequest.New().
+//	  Get("https://api.github.com/users/{user}/repos/{repo}").
+//	  PathParams(map[string]string{"user": "parnurzeal", "repo": "gorequest"}).
+//	  End()
+func (s *SuperAgent) PathParams(content interface{}) *SuperAgent {
+	if s.pathParams == nil {
+		s.pathParams = make(map[string]interface{})
+	}
+	v := reflect.Indirect(reflect.ValueOf(content))
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			s.Errors = append(s.Errors, errors.New("PathParams func: map keys must be strings"))
+			return s
+		}
+		for _, key := range v.MapKeys() {
+			s.pathParams[key.String()] = v.MapIndex(key).Interface()
+		}
+	case reflect.Struct:
+		fields, err := structTagFields(v, TagPath)
+		if err != nil {
+			s.Errors = append(s.Errors, err)
+			return s
+		}
+		for _, f := range fields {
+			if f.isArray {
+				s.pathParams[f.name] = f.values
+			} else {
+				s.pathParams[f.name] = f.values[0]
+			}
+		}
+	default:
+		s.Errors = append(s.Errors, errors.New("PathParams func: expected a map or a struct but got "+v.Kind().String()))
+	}
+	return s
+}
+
+// A uriTemplateOperator describes how an expression is expanded, see RFC 6570 appendix A.
+type uriTemplateOperator struct {
+	first         string
+	sep           string
+	named         bool
+	ifEmpty       string
+	allowReserved bool
+}
+
+var uriTemplateOperators = map[byte]uriTemplateOperator{
+	0:   {"", ",", false, "", false},
+	'+': {"", ",", false, "", true},
+	'#': {"#", ",", false, "", true},
+	'.': {".", ".", false, "", false},
+	'/': {"/", "/", false, "", false},
+	';': {";", ";", true, "", false},
+	'?': {"?", "&", true, "=", false},
+	'&': {"&", "&", true, "=", false},
+}
+
+// A uriTemplateVar is a variable of an expression, e.g. "tags*" or "q:3".
+type uriTemplateVar struct {
+	name    string
+	explode bool
+	prefix  int
+}
+
+// expandURITemplate expands every expression of the RFC 6570 template with params.
+// Braces which don't hold a valid expression are copied as they are.
+func expandURITemplate(template string, params map[string]interface{}) (string, error) {
+	var buf strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		buf.WriteString(template[:start])
+		op, vars, ok := parseURITemplateExpression(template[start+1 : end])
+		if !ok {
+			buf.WriteString(template[start : end+1])
+		} else if err := expandURITemplateExpression(&buf, op, vars, params); err != nil {
+			return "", err
+		}
+		template = template[end+1:]
+	}
+	buf.WriteString(template)
+	return buf.String(), nil
+}
+
+func parseURITemplateExpression(expression string) (byte, []uriTemplateVar, bool) {
+	var op byte
+	if expression != "" {
+		if _, ok := uriTemplateOperators[expression[0]]; ok && expression[0] != 0 {
+			op = expression[0]
+			expression = expression[1:]
+		}
+	}
+	if expression == "" {
+		return 0, nil, false
+	}
+	var vars []uriTemplateVar
+	for _, spec := range strings.Split(expression, ",") {
+		v := uriTemplateVar{name: spec}
+		if strings.HasSuffix(spec, "*") {
+			v.name = spec[:len(spec)-1]
+			v.explode = true
+		} else if i := strings.IndexByte(spec, ':'); i >= 0 {
+			prefix, err := strconv.Atoi(spec[i+1:])
+			if err != nil || prefix <= 0 || prefix >= 10000 {
+				return 0, nil, false
+			}
+			v.name = spec[:i]
+			v.prefix = prefix
+		}
+		if !isURITemplateVarName(v.name) {
+			return 0, nil, false
+		}
+		vars = append(vars, v)
+	}
+	return op, vars, true
+}
+
+func isURITemplateVarName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '_', c == '.':
+		case c == '%' && i+2 < len(name) && isHex(name[i+1]) && isHex(name[i+2]):
+			i += 2
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func expandURITemplateExpression(buf *strings.Builder, opChar byte, vars []uriTemplateVar, params map[string]interface{}) error {
+	op := uriTemplateOperators[opChar]
+	first := true
+	for _, v := range vars {
+		value, defined := params[v.name]
+		var (
+			str   string
+			list  []string
+			assoc [][2]string
+			err   error
+		)
+		if defined {
+			str, list, assoc, defined, err = uriTemplateValue(value)
+			if err != nil {
+				return errors.Wrap(err, "PathParam \""+v.name+"\"")
+			}
+		}
+		if !defined {
+			if opChar == 0 {
+				return errors.New("PathParam: missing value for \"{" + v.name + "}\" in url")
+			}
+			if opChar == '+' {
+				return errors.New("PathParam: missing value for \"{+" + v.name + "}\" in url")
+			}
+			continue
+		}
+
+		if first {
+			buf.WriteString(op.first)
+			first = false
+		} else {
+			buf.WriteString(op.sep)
+		}
+		switch {
+		case list == nil && assoc == nil:
+			if v.prefix > 0 {
+				str = truncateRunes(str, v.prefix)
+			}
+			if op.named {
+				buf.WriteString(v.name)
+				if str == "" {
+					buf.WriteString(op.ifEmpty)
+					continue
+				}
+				buf.WriteByte('=')
+			}
+			buf.WriteString(uriTemplateEscape(str, op.allowReserved))
+		case !v.explode:
+			if op.named {
+				buf.WriteString(v.name)
+				buf.WriteByte('=')
+			}
+			if list != nil {
+				for i, item := range list {
+					if i > 0 {
+						buf.WriteByte(',')
+					}
+					buf.WriteString(uriTemplateEscape(item, op.allowReserved))
+				}
+			} else {
+				for i, pair := range assoc {
+					if i > 0 {
+						buf.WriteByte(',')
+					}
+					buf.WriteString(uriTemplateEscape(pair[0], op.allowReserved))
+					buf.WriteByte(',')
+					buf.WriteString(uriTemplateEscape(pair[1], op.allowReserved))
+				}
+			}
+		case list != nil:
+			for i, item := range list {
+				if i > 0 {
+					buf.WriteString(op.sep)
+				}
+				if op.named {
+					buf.WriteString(v.name)
+					if item == "" {
+						buf.WriteString(op.ifEmpty)
+						continue
+					}
+					buf.WriteByte('=')
+				}
+				buf.WriteString(uriTemplateEscape(item, op.allowReserved))
+			}
+		default:
+			for i, pair := range assoc {
+				if i > 0 {
+					buf.WriteString(op.sep)
+				}
+				buf.WriteString(uriTemplateEscape(pair[0], op.allowReserved))
+				if op.named && pair[1] == "" {
+					buf.WriteString(op.ifEmpty)
+					continue
+				}
+				buf.WriteByte('=')
+				buf.WriteString(uriTemplateEscape(pair[1], op.allowReserved))
+			}
+		}
+	}
+	return nil
+}
+
+// uriTemplateValue turns a path parameter into a string, a list or an ordered map of strings.
+// Nil values, empty lists and empty maps are undefined.
+func uriTemplateValue(value interface{}) (str string, list []string, assoc [][2]string, defined bool, err error) {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil, nil, false, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return "", nil, nil, false, nil
+	}
+	scalarOptions := []string{""}
+	if str, ok, err := formatTagValue(v, scalarOptions, ""); err != nil || ok {
+		return str, nil, nil, ok, err
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		list = make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, ok, err := formatTagValue(v.Index(i), scalarOptions, "")
+			if err != nil {
+				return "", nil, nil, false, err
+			}
+			if !ok {
+				return "", nil, nil, false, errors.New("unsupported element type " + v.Type().Elem().String())
+			}
+			list = append(list, item)
+		}
+		return "", list, nil, len(list) != 0, nil
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		assoc = make([][2]string, 0, len(keys))
+		for _, key := range keys {
+			k, ok, err := formatTagValue(key, scalarOptions, "")
+			if err != nil {
+				return "", nil, nil, false, err
+			}
+			item, ok2, err := formatTagValue(v.MapIndex(key), scalarOptions, "")
+			if err != nil {
+				return "", nil, nil, false, err
+			}
+			if !ok || !ok2 {
+				return "", nil, nil, false, errors.New("unsupported map type " + v.Type().String())
+			}
+			assoc = append(assoc, [2]string{k, item})
+		}
+		return "", nil, assoc, len(assoc) != 0, nil
+	}
+	return "", nil, nil, false, errors.New("unsupported type " + v.Type().String())
+}
+
+// uriTemplateEscape percent-encodes everything but unreserved characters, and
+// also keeps reserved characters and percent-encoded triplets if allowReserved is set.
+func uriTemplateEscape(s string, allowReserved bool) string {
+	const hex = "0123456789ABCDEF"
+	var buf strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', strings.IndexByte("-._~", c) >= 0:
+			buf.WriteByte(c)
+		case allowReserved && strings.IndexByte(":/?#[]@!$&'()*+,;=", c) >= 0:
+			buf.WriteByte(c)
+		case allowReserved && c == '%' && i+2 < len(s) && isHex(s[i+1]) && isHex(s[i+2]):
+			buf.WriteByte(c)
+		default:
+			buf.WriteByte('%')
+			buf.WriteByte(hex[c>>4])
+			buf.WriteByte(hex[c&15])
+		}
+	}
+	return buf.String()
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	i := 0
+	for ; n > 0; n-- {
+		_, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+	}
+	return s[:i]
+}
@@ -0,0 +1,136 @@
+package gorequest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Examples from RFC 6570 section 3.2
+func TestExpandURITemplate(t *testing.T) {
+	params := map[string]interface{}{
+		"count":      []string{"one", "two", "three"},
+		"dom":        []string{"example", "com"},
+		"dub":        "me/too",
+		"hello":      "Hello World!",
+		"half":       "50%",
+		"var":        "value",
+		"who":        "fred",
+		"base":       "http://example.com/home/",
+		"path":       "/foo/bar",
+		"list":       []string{"red", "green", "blue"},
+		"keys":       map[string]string{"semi": ";", "dot": ".", "comma": ","},
+		"v":          6,
+		"x":          1024,
+		"y":          768,
+		"empty":      "",
+		"empty_keys": map[string]string{},
+		"undef":      nil,
+	}
+	var cases = []struct {
+		template string
+		want     string
+	}{
+		{"{var}", "value"},
+		{"{hello}", "Hello%20World%21"},
+		{"{half}", "50%25"},
+		{"O{empty}X", "OX"},
+		{"{x,y}", "1024,768"},
+		{"{x,hello,y}", "1024,Hello%20World%21,768"},
+		{"?{x,empty}", "?1024,"},
+		{"{var:3}", "val"},
+		{"{var:30}", "value"},
+		{"{list}", "red,green,blue"},
+		{"{list*}", "red,green,blue"},
+		{"{keys}", "comma,%2C,dot,.,semi,%3B"},
+		{"{keys*}", "comma=%2C,dot=.,semi=%3B"},
+		{"{+var}", "value"},
+		{"{+hello}", "Hello%20World!"},
+		{"{+half}", "50%25"},
+		{"{base}index", "http%3A%2F%2Fexample.com%2Fhome%2Findex"},
+		{"{+base}index", "http://example.com/home/index"},
+		{"{+path}/here", "/foo/bar/here"},
+		{"here?ref={+path}", "here?ref=/foo/bar"},
+		{"{+path:6}/here", "/foo/b/here"},
+		{"{#var}", "#value"},
+		{"{#hello}", "#Hello%20World!"},
+		{"{#path:6}/here", "#/foo/b/here"},
+		{"{#keys*}", "#comma=,,dot=.,semi=;"},
+		{"X{.var}", "X.value"},
+		{"X{.x,y}", "X.1024.768"},
+		{"X{.list*}", "X.red.green.blue"},
+		{"X{.empty_keys}", "X"},
+		{"{/var}", "/value"},
+		{"{/var,x}/here", "/value/1024/here"},
+		{"{/list*,path:4}", "/red/green/blue/%2Ffoo"},
+		{"{/undef}", ""},
+		{"{;x,y}", ";x=1024;y=768"},
+		{"{;x,y,empty}", ";x=1024;y=768;empty"},
+		{"{;list*}", ";list=red;list=green;list=blue"},
+		{"{;keys*}", ";comma=%2C;dot=.;semi=%3B"},
+		{"{?x,y}", "?x=1024&y=768"},
+		{"{?x,y,empty}", "?x=1024&y=768&empty="},
+		{"{?var:3}", "?var=val"},
+		{"{?list}", "?list=red,green,blue"},
+		{"{?list*}", "?list=red&list=green&list=blue"},
+		{"{?keys}", "?keys=comma,%2C,dot,.,semi,%3B"},
+		{"{?keys*}", "?comma=%2C&dot=.&semi=%3B"},
+		{"?fixed=yes{&x}", "?fixed=yes&x=1024"},
+		{"{&list*}", "&list=red&list=green&list=blue"},
+		{"{?who,undef}", "?who=fred"},
+		{`?filter={"a":1}`, `?filter={"a":1}`},
+	}
+	for _, c := range cases {
+		got, err := expandURITemplate(c.template, params)
+		if err != nil {
+			t.Error(fmt.Sprintf("Expected no error for %q | but got %v", c.template, err))
+		} else if got != c.want {
+			t.Error(fmt.Sprintf("Expected %q to expand to %q | but got %q", c.template, c.want, got))
+		}
+	}
+
+	for _, template := range []string{"/users/{id}", "/{+undef}"} {
+		if _, err := expandURITemplate(template, params); err == nil {
+			t.Error(fmt.Sprintf("Expected missing value error for %q", template))
+		}
+	}
+}
+
+func TestPathParams(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		want := "/users/..%2Fadmin/repos/go%20request"
+		if r.URL.EscapedPath() != want {
+			t.Error(fmt.Sprintf("Expected path %q | but got %q", want, r.URL.EscapedPath()))
+		}
+		if r.URL.RawQuery != "tab=stars" {
+			t.Error(fmt.Sprintf("Expected query %q | but got %q", "tab=stars", r.URL.RawQuery))
+		}
+	}))
+	defer ts.Close()
+
+	_, _, errs := New().Get(ts.URL+"/users/{id}/repos/{repo}{?tab}").
+		PathParam("id", "../admin").
+		PathParams(map[string]string{"repo": "go request", "tab": "stars"}).
+		End()
+	if errs != nil {
+		t.Error(errs)
+	}
+
+	_, _, errs = New().Get(ts.URL + "/users/{id}/repos/{repo}").
+		PathParams(struct {
+			ID   int    `path:"id"`
+			Repo string `path:"repo,omitempty"`
+		}{ID: 42}).
+		End()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "{repo}") {
+		t.Error(fmt.Sprintf("Expected missing {repo} error | but got %v", errs))
+	}
+
+	// without PathParam, braces are literal
+	req, err := New().Get(ts.URL + "/users/{id}/search?q={x}").MakeRequest()
+	if err != nil || req.URL.Path != "/users/{id}/search" || req.URL.Query().Get("q") != "{x}" {
+		t.Error(fmt.Sprintf("Expected the url as it is | but got %v, %v", req, err))
+	}
+}
@@ -0,0 +1,678 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PostmanSchema is the schema of the collections read and written, Postman collection v2.1.
+const PostmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// A PostmanCollection is a Postman v2.1 collection. Collections are read by
+// LoadPostmanCollection and turned into requests by Requests, or built from requests by
+// NewPostmanCollection and Add. Only the fields describing requests are kept.
+type PostmanCollection struct {
+	Info     PostmanInfo       `json:"info"`
+	Item     []PostmanItem     `json:"item"`
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+	Auth     *PostmanAuth      `json:"auth,omitempty"`
+}
+
+type PostmanInfo struct {
+	PostmanID   string `json:"_postman_id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// A PostmanItem is a request, or a folder of items.
+type PostmanItem struct {
+	Name    string              `json:"name"`
+	Item    []PostmanItem       `json:"item,omitempty"`
+	Request *PostmanItemRequest `json:"request,omitempty"`
+	Auth    *PostmanAuth        `json:"auth,omitempty"`
+}
+
+type PostmanItemRequest struct {
+	Method      string            `json:"method"`
+	Header      []PostmanKeyValue `json:"header"`
+	Body        *PostmanBody      `json:"body,omitempty"`
+	URL         PostmanURL        `json:"url"`
+	Auth        *PostmanAuth      `json:"auth,omitempty"`
+	Description string            `json:"description,omitempty"`
+}
+
+// A PostmanURL is an url, written either as a string or as its parts.
+type PostmanURL struct {
+	Raw      string            `json:"raw,omitempty"`
+	Protocol string            `json:"protocol,omitempty"`
+	Host     PostmanStrings    `json:"host,omitempty"`
+	Port     string            `json:"port,omitempty"`
+	Path     PostmanStrings    `json:"path,omitempty"`
+	Query    []PostmanKeyValue `json:"query,omitempty"`
+	// Variable are the values of the ":name" segments of the path.
+	Variable []PostmanKeyValue `json:"variable,omitempty"`
+}
+
+func (u *PostmanURL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if json.Unmarshal(data, &raw) == nil {
+		*u = PostmanURL{Raw: raw}
+		return nil
+	}
+	type plain PostmanURL
+	return json.Unmarshal(data, (*plain)(u))
+}
+
+// String returns the raw url, or the url made of its parts.
+func (u *PostmanURL) String() string {
+	if u.Raw != "" {
+		return u.Raw
+	}
+	var out strings.Builder
+	if u.Protocol != "" {
+		out.WriteString(u.Protocol + "://")
+	}
+	out.WriteString(strings.Join(u.Host, "."))
+	if u.Port != "" {
+		out.WriteString(":" + u.Port)
+	}
+	if len(u.Path) != 0 {
+		out.WriteString("/" + strings.Join(u.Path, "/"))
+	}
+	var query []string
+	for _, param := range u.Query {
+		if !param.Disabled {
+			query = append(query, param.Key+"="+param.Value)
+		}
+	}
+	if len(query) != 0 {
+		out.WriteString("?" + strings.Join(query, "&"))
+	}
+	return out.String()
+}
+
+// PostmanStrings are strings, written either as a string or as an array.
+type PostmanStrings []string
+
+func (s *PostmanStrings) UnmarshalJSON(data []byte) error {
+	var one string
+	if json.Unmarshal(data, &one) == nil {
+		*s = PostmanStrings{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(s))
+}
+
+// A PostmanKeyValue is a header, a query parameter, a form field or a variable. Values
+// which aren't strings in the collection are kept as JSON.
+type PostmanKeyValue struct {
+	Key      string         `json:"key"`
+	Value    string         `json:"value,omitempty"`
+	Type     string         `json:"type,omitempty"`
+	Src      PostmanStrings `json:"src,omitempty"`
+	Disabled bool           `json:"disabled,omitempty"`
+}
+
+func (kv *PostmanKeyValue) UnmarshalJSON(data []byte) error {
+	type plain PostmanKeyValue
+	var aux struct {
+		plain
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*kv = PostmanKeyValue(aux.plain)
+	if len(aux.Value) != 0 && json.Unmarshal(aux.Value, &kv.Value) != nil && string(aux.Value) != "null" {
+		kv.Value = string(aux.Value)
+	}
+	return nil
+}
+
+type PostmanBody struct {
+	// Mode is raw, urlencoded, formdata, file or graphql.
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw,omitempty"`
+	URLEncoded []PostmanKeyValue `json:"urlencoded,omitempty"`
+	FormData   []PostmanKeyValue `json:"formdata,omitempty"`
+	File       *struct {
+		Src string `json:"src"`
+	} `json:"file,omitempty"`
+	GraphQL *struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables,omitempty"`
+	} `json:"graphql,omitempty"`
+	Options *PostmanBodyOptions `json:"options,omitempty"`
+}
+
+type PostmanBodyOptions struct {
+	Raw struct {
+		// Language is json, xml, html, javascript or text.
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+// A PostmanAuth is the authentication of a request, folder or collection. The basic,
+// bearer, apikey and noauth types are supported, and oauth2 with an access token.
+type PostmanAuth struct {
+	Type   string            `json:"type"`
+	Basic  []PostmanKeyValue `json:"basic,omitempty"`
+	Bearer []PostmanKeyValue `json:"bearer,omitempty"`
+	APIKey []PostmanKeyValue `json:"apikey,omitempty"`
+	OAuth2 []PostmanKeyValue `json:"oauth2,omitempty"`
+}
+
+// A PostmanRequest is a request of a collection, see PostmanCollection.Requests.
+type PostmanRequest struct {
+	// Name is the name of the item, after the names of its folders: "Users/Create user".
+	Name  string
+	Agent *SuperAgent
+}
+
+// postmanLanguages are the Content-Type headers sent for the languages of raw bodies.
+var postmanLanguages = map[string]string{
+	"json":       "application/json",
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"javascript": "application/javascript",
+	"text":       "text/plain",
+}
+
+var postmanPathVariable = regexp.MustCompile(`/:([A-Za-z_][\w-]*)`)
+
+// LoadPostmanCollection reads the Postman v2.1 collection filename.
+func LoadPostmanCollection(filename string) (*PostmanCollection, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var c PostmanCollection
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, errors.Wrapf(err, "gorequest: invalid Postman collection %s", filename)
+	}
+	if c.Info.Schema != "" && !strings.Contains(c.Info.Schema, "/v2.") {
+		return nil, errors.Errorf("gorequest: unsupported Postman collection schema %s, expected v2.1", c.Info.Schema)
+	}
+	return &c, nil
+}
+
+// LoadPostmanEnvironment returns the enabled variables of the Postman environment filename.
+func LoadPostmanEnvironment(filename string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var env struct {
+		Values []json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, errors.Wrapf(err, "gorequest: invalid Postman environment %s", filename)
+	}
+	variables := map[string]string{}
+	for _, raw := range env.Values {
+		// enabled is decoded on its own, as PostmanKeyValue.UnmarshalJSON would hide it
+		var value PostmanKeyValue
+		var enabled struct {
+			Enabled *bool `json:"enabled"`
+		}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return nil, errors.Wrapf(err, "gorequest: invalid Postman environment %s", filename)
+		}
+		json.Unmarshal(raw, &enabled)
+		if enabled.Enabled == nil || *enabled.Enabled {
+			variables[value.Key] = value.Value
+		}
+	}
+	return variables, nil
+}
+
+// Requests returns the requests of the collection, folders flattened, each started from
+// client, or from a new Client if client is nil. The variables of env, such as the ones
+// of LoadPostmanEnvironment, override the variables of the collection:
+//
+//	collection, err := gorequest.LoadPostmanCollection("partner.postman_collection.json")
+//	env, err := gorequest.LoadPostmanEnvironment("partner.postman_environment.json")
+//	requests, err := collection.Requests(nil, env)
+//	for _, request := range requests {
+//	  resp, body, errs := request.Agent.End()
+//	}
+//
+// Variables are replaced in urls, headers, bodies and authentications, and the system
+// variables {{$guid}}, {{$timestamp}} and {{$randomInt}} are supported. Unknown variables
+// are errors, as are scripts-only features such as unsupported authentications.
+func (c *PostmanCollection) Requests(client *Client, env map[string]string) ([]PostmanRequest, error) {
+	if client == nil {
+		client = NewClient("")
+	}
+	variables := map[string]string{}
+	for _, variable := range c.Variable {
+		if !variable.Disabled {
+			variables[variable.Key] = variable.Value
+		}
+	}
+	for key, value := range env {
+		variables[key] = value
+	}
+	var requests []PostmanRequest
+	err := walkPostmanItems(c.Item, "", c.Auth, func(name string, item *PostmanItem, auth *PostmanAuth) error {
+		agent, err := item.Request.agent(client, variables, auth)
+		if err != nil {
+			return errors.Wrapf(err, "gorequest: Postman request %s", name)
+		}
+		requests = append(requests, PostmanRequest{Name: name, Agent: agent})
+		return nil
+	})
+	return requests, err
+}
+
+// walkPostmanItems calls fn for the requests of items and their folders, with the
+// authentication they inherit.
+func walkPostmanItems(items []PostmanItem, prefix string, auth *PostmanAuth, fn func(name string, item *PostmanItem, auth *PostmanAuth) error) error {
+	for i := range items {
+		item := &items[i]
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+		if item.Request == nil {
+			if err := walkPostmanItems(item.Item, prefix+item.Name+"/", itemAuth, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		if item.Request.Auth != nil {
+			itemAuth = item.Request.Auth
+		}
+		if err := fn(prefix+item.Name, item, itemAuth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// agent returns a SuperAgent sending the request.
+func (r *PostmanItemRequest) agent(client *Client, variables map[string]string, auth *PostmanAuth) (*SuperAgent, error) {
+	replace := func(s string) (string, error) { return replacePostmanVariables(s, variables, 0) }
+
+	target, err := replace(r.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	pathVariables := map[string]string{}
+	for _, variable := range r.URL.Variable {
+		if pathVariables[variable.Key], err = replace(variable.Value); err != nil {
+			return nil, err
+		}
+	}
+	target = postmanPathVariable.ReplaceAllStringFunc(target, func(segment string) string {
+		if value, ok := pathVariables[segment[2:]]; ok {
+			return "/" + url.PathEscape(value)
+		}
+		return segment
+	})
+	if !strings.Contains(target, "://") {
+		target = "http://" + target
+	}
+	method := strings.ToUpper(r.Method)
+	if method == "" {
+		method = GET
+	}
+
+	s := client.CustomMethod(method, target)
+	for _, header := range r.Header {
+		if header.Disabled {
+			continue
+		}
+		name, err := replace(header.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := replace(header.Value)
+		if err != nil {
+			return nil, err
+		}
+		s.AppendHeader(name, value)
+	}
+	if err := setPostmanAuth(s, auth, replace); err != nil {
+		return nil, err
+	}
+	if err := r.Body.send(s, replace); err != nil {
+		return nil, err
+	}
+	if len(s.Errors) != 0 {
+		return nil, s.Errors[0]
+	}
+	return s, nil
+}
+
+// send sets the body of s.
+func (b *PostmanBody) send(s *SuperAgent, replace func(string) (string, error)) error {
+	if b == nil {
+		return nil
+	}
+	switch b.Mode {
+	case "", "raw":
+		body, err := replace(b.Raw)
+		if err != nil || body == "" {
+			return err
+		}
+		if b.Options != nil && s.Header.Get("Content-Type") == "" {
+			if contentType, ok := postmanLanguages[b.Options.Raw.Language]; ok {
+				s.Set("Content-Type", contentType)
+			}
+		}
+		s.Type(TypeText).SendString(body)
+	case "urlencoded", "formdata":
+		if b.Mode == "urlencoded" {
+			s.Type(TypeForm)
+		} else {
+			s.Type(TypeMultipart)
+		}
+		fields := b.URLEncoded
+		if b.Mode == "formdata" {
+			fields = b.FormData
+		}
+		for _, field := range fields {
+			if field.Disabled {
+				continue
+			}
+			key, err := replace(field.Key)
+			if err != nil {
+				return err
+			}
+			if field.Type == "file" {
+				for _, src := range field.Src {
+					s.SendFile(src, "", key, true)
+				}
+				continue
+			}
+			value, err := replace(field.Value)
+			if err != nil {
+				return err
+			}
+			s.FormData.Add(key, value)
+		}
+	case "file":
+		if b.File == nil || b.File.Src == "" {
+			return nil
+		}
+		data, err := ioutil.ReadFile(b.File.Src)
+		if err != nil {
+			return err
+		}
+		s.Type(TypeText).SendString(string(data))
+	case "graphql":
+		if b.GraphQL == nil {
+			return nil
+		}
+		query, err := replace(b.GraphQL.Query)
+		if err != nil {
+			return err
+		}
+		variables, err := replace(b.GraphQL.Variables)
+		if err != nil {
+			return err
+		}
+		payload := map[string]interface{}{"query": query}
+		if strings.TrimSpace(variables) != "" {
+			payload["variables"] = json.RawMessage(variables)
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return errors.Wrap(err, "invalid GraphQL variables")
+		}
+		s.Type(TypeJSON).SendString(string(data))
+	default:
+		return errors.Errorf("unsupported body mode %q", b.Mode)
+	}
+	return nil
+}
+
+// setPostmanAuth sets the authentication of s.
+func setPostmanAuth(s *SuperAgent, auth *PostmanAuth, replace func(string) (string, error)) error {
+	if auth == nil {
+		return nil
+	}
+	param := func(params []PostmanKeyValue, key string) (string, error) {
+		for _, p := range params {
+			if p.Key == key {
+				return replace(p.Value)
+			}
+		}
+		return "", nil
+	}
+	switch auth.Type {
+	case "", "noauth":
+	case "basic":
+		username, err := param(auth.Basic, "username")
+		if err != nil {
+			return err
+		}
+		password, err := param(auth.Basic, "password")
+		if err != nil {
+			return err
+		}
+		s.SetBasicAuth(username, password)
+	case "bearer", "oauth2":
+		params, key := auth.Bearer, "token"
+		if auth.Type == "oauth2" {
+			params, key = auth.OAuth2, "accessToken"
+		}
+		token, err := param(params, key)
+		if err != nil {
+			return err
+		}
+		if token == "" {
+			return errors.Errorf("%s authentication without token", auth.Type)
+		}
+		s.Set("Authorization", "Bearer "+token)
+	case "apikey":
+		key, err := param(auth.APIKey, "key")
+		if err != nil {
+			return err
+		}
+		value, err := param(auth.APIKey, "value")
+		if err != nil {
+			return err
+		}
+		if in, _ := param(auth.APIKey, "in"); in == "query" {
+			s.Param(key, value)
+		} else {
+			s.Set(key, value)
+		}
+	default:
+		return errors.Errorf("unsupported authentication %q", auth.Type)
+	}
+	return nil
+}
+
+// replacePostmanVariables replaces the {{name}} variables of s. depth counts the nested
+// replacements, to detect cycles.
+func replacePostmanVariables(s string, variables map[string]string, depth int) (string, error) {
+	if depth > 10 {
+		return "", errors.Errorf("variables nested too deeply in %q", s)
+	}
+	var err error
+	replaced := httpFileRef.ReplaceAllStringFunc(s, func(ref string) string {
+		if err != nil {
+			return ref
+		}
+		name := httpFileRef.FindStringSubmatch(ref)[1]
+		var value string
+		if strings.HasPrefix(name, "$") {
+			value, err = systemVariable(name)
+		} else if raw, ok := variables[name]; ok {
+			value, err = replacePostmanVariables(raw, variables, depth+1)
+		} else {
+			err = errors.Errorf("unknown variable %q", name)
+		}
+		return value
+	})
+	return replaced, err
+}
+
+// NewPostmanCollection returns an empty collection named name, see Add.
+func NewPostmanCollection(name string) *PostmanCollection {
+	id, _ := systemVariable("$guid")
+	return &PostmanCollection{Info: PostmanInfo{PostmanID: id, Name: name, Schema: PostmanSchema}}
+}
+
+// Add adds the request End would send to the collection, secrets redacted (see
+// SetRedaction). Slashes in name put the request in folders, "Users/Create user" adds
+// "Create user" to the "Users" folder:
+//
+//	collection := gorequest.NewPostmanCollection("Users API")
+//	collection.Add("Users/Create user", client.Post("/users").Send(user))
+//	collection.Add("Users/List users", client.Get("/users"))
+//	err := collection.WriteFile("users.postman_collection.json")
+//
+// Multipart files are referenced by name, as Postman collections don't hold them.
+func (c *PostmanCollection) Add(name string, s *SuperAgent) error {
+	if len(s.Errors) != 0 {
+		return s.Errors[0]
+	}
+	s.resolveTargetType()
+	req, err := s.MakeRequest()
+	if err != nil {
+		return err
+	}
+	r, err := s.redaction().redactRequest(req)
+	if err != nil {
+		return err
+	}
+	body, err := readBody(&r.Body)
+	if err != nil {
+		return err
+	}
+
+	request := &PostmanItemRequest{Method: r.Method, Header: []PostmanKeyValue{}, URL: postmanURL(r.URL)}
+	for _, header := range exportedHeaders(r) {
+		for _, value := range r.Header.Values(header) {
+			request.Header = append(request.Header, PostmanKeyValue{Key: header, Value: value})
+		}
+	}
+	if len(body) != 0 {
+		request.Body = postmanBody(r.Header.Get("Content-Type"), body)
+	}
+
+	items := &c.Item
+	path := strings.Split(name, "/")
+	for _, folder := range path[:len(path)-1] {
+		found := false
+		for i := range *items {
+			if (*items)[i].Name == folder && (*items)[i].Request == nil {
+				items, found = &(*items)[i].Item, true
+				break
+			}
+		}
+		if !found {
+			*items = append(*items, PostmanItem{Name: folder, Item: []PostmanItem{}})
+			items = &(*items)[len(*items)-1].Item
+		}
+	}
+	*items = append(*items, PostmanItem{Name: path[len(path)-1], Request: request})
+	return nil
+}
+
+func postmanURL(u *url.URL) PostmanURL {
+	postman := PostmanURL{
+		Raw:      u.String(),
+		Protocol: u.Scheme,
+		Host:     strings.Split(u.Hostname(), "."),
+		Port:     u.Port(),
+	}
+	if path := strings.Trim(u.EscapedPath(), "/"); path != "" {
+		postman.Path = strings.Split(path, "/")
+	}
+	for _, pair := range strings.Split(u.RawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		key, _ := url.QueryUnescape(kv[0])
+		param := PostmanKeyValue{Key: key}
+		if len(kv) == 2 {
+			param.Value, _ = url.QueryUnescape(kv[1])
+		}
+		postman.Query = append(postman.Query, param)
+	}
+	return postman
+}
+
+// postmanBody returns the Postman body of a request body.
+func postmanBody(contentType string, body []byte) *PostmanBody {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		b := &PostmanBody{Mode: "urlencoded"}
+		for _, pair := range strings.Split(string(body), "&") {
+			kv := strings.SplitN(pair, "=", 2)
+			key, _ := url.QueryUnescape(kv[0])
+			field := PostmanKeyValue{Key: key}
+			if len(kv) == 2 {
+				field.Value, _ = url.QueryUnescape(kv[1])
+			}
+			b.URLEncoded = append(b.URLEncoded, field)
+		}
+		return b
+	case mediaType == "multipart/form-data" && params["boundary"] != "":
+		b := &PostmanBody{Mode: "formdata"}
+		reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				break
+			}
+			if part.FileName() != "" {
+				b.FormData = append(b.FormData, PostmanKeyValue{Key: part.FormName(), Type: "file", Src: PostmanStrings{part.FileName()}})
+				continue
+			}
+			value, _ := ioutil.ReadAll(part)
+			b.FormData = append(b.FormData, PostmanKeyValue{Key: part.FormName(), Value: string(value), Type: "text"})
+		}
+		return b
+	}
+	b := &PostmanBody{Mode: "raw", Raw: string(body)}
+	for language, languageType := range postmanLanguages {
+		if mediaType == languageType || (language == "json" && strings.HasSuffix(mediaType, "+json")) ||
+			(language == "xml" && (mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"))) {
+			b.Options = &PostmanBodyOptions{}
+			b.Options.Raw.Language = language
+		}
+	}
+	return b
+}
+
+// WriteTo writes the collection as indented JSON to w.
+func (c *PostmanCollection) WriteTo(w io.Writer) (int64, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	n, err := w.Write(append(data, '\n'))
+	return int64(n), err
+}
+
+// WriteFile writes the collection as indented JSON to filename.
+func (c *PostmanCollection) WriteFile(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := c.WriteTo(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
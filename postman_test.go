@@ -0,0 +1,165 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPostmanCollection = `{
+  "info": {"name": "Users", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "variable": [{"key": "base", "value": "{{host}}/api"}, {"key": "host", "value": "http://invalid"}],
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "item": [
+    {"name": "Users", "item": [
+      {"name": "Create user", "request": {
+        "method": "POST",
+        "header": [{"key": "X-Trace", "value": "{{$guid}}"}, {"key": "X-Off", "value": "1", "disabled": true}],
+        "body": {"mode": "raw", "raw": "{\"name\":\"{{name}}\"}", "options": {"raw": {"language": "json"}}},
+        "url": {"raw": "{{base}}/users/:id?verbose=1", "host": ["{{base}}"], "path": ["users", ":id"],
+          "variable": [{"key": "id", "value": "42"}]}
+      }},
+      {"name": "Login", "request": {
+        "method": "POST",
+        "auth": {"type": "basic", "basic": [{"key": "username", "value": "gopher"}, {"key": "password", "value": "secret"}]},
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "remember", "value": "true"}, {"key": "off", "value": "1", "disabled": true}]},
+        "url": "{{base}}/login"
+      }}
+    ]},
+    {"name": "Health", "auth": {"type": "noauth"}, "request": {"method": "GET", "url": "{{base}}/health"}}
+  ]
+}`
+
+func TestPostmanCollection(t *testing.T) {
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		got = append(got, fmt.Sprintf("%s %s %s %s %s %t", r.Method, r.URL.RequestURI(), r.Header.Get("Authorization"),
+			r.Header.Get("Content-Type"), body, len(r.Header.Get("X-Trace")) == 36 && r.Header.Get("X-Off") == ""))
+	}))
+	defer ts.Close()
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "users.postman_collection.json")
+	ioutil.WriteFile(filename, []byte(testPostmanCollection), 0644)
+	envFilename := filepath.Join(dir, "dev.postman_environment.json")
+	ioutil.WriteFile(envFilename, []byte(`{"name": "dev", "values": [
+	  {"key": "host", "value": "`+ts.URL+`", "enabled": true},
+	  {"key": "token", "value": "t0k3n", "enabled": true},
+	  {"key": "name", "value": "disabled", "enabled": false},
+	  {"key": "name", "value": "gopher"},
+	  {"key": "token", "value": "disabled", "enabled": false}
+	]}`), 0644)
+
+	collection, err := LoadPostmanCollection(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := LoadPostmanEnvironment(envFilename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests, err := collection.Requests(nil, env)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, request := range requests {
+		names = append(names, request.Name)
+		if _, _, errs := request.Agent.End(); errs != nil {
+			t.Fatal(errs)
+		}
+	}
+	if expected := "Users/Create user,Users/Login,Health"; strings.Join(names, ",") != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %v", expected, names))
+	}
+	expected := []string{
+		`POST /api/users/42?verbose=1 Bearer t0k3n application/json {"name":"gopher"} true`,
+		"POST /api/login Basic Z29waGVyOnNlY3JldA== application/x-www-form-urlencoded remember=true false",
+		"GET /api/health    false",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Error(fmt.Sprintf("Expected %q | but got %q", expected, got))
+	}
+
+	delete(env, "token")
+	if _, err := collection.Requests(nil, env); err == nil || !strings.Contains(err.Error(), `Postman request Users/Create user: unknown variable "token"`) {
+		t.Error(fmt.Sprintf("Expected an unknown variable error | but got %v", err))
+	}
+}
+
+func TestPostmanCollectionAdd(t *testing.T) {
+	collection := NewPostmanCollection("Users")
+	client := NewClient("http://example.com/api")
+	if err := collection.Add("Users/Create user", client.Post("/users").Query("verbose=1").Set("Authorization", "Bearer secret").Send(`{"name":"gopher"}`)); err != nil {
+		t.Fatal(err)
+	}
+	collection.Add("Users/Login", client.Post("/login").Type(TypeForm).Send("user=gopher&remember=true"))
+	collection.Add("Health", client.Get("/health"))
+
+	var buf bytes.Buffer
+	if _, err := collection.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var written PostmanCollection
+	if err := json.Unmarshal(buf.Bytes(), &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.Info.Schema != PostmanSchema || len(written.Item) != 2 || len(written.Item[0].Item) != 2 || written.Item[1].Name != "Health" {
+		t.Fatal(fmt.Sprintf("Expected a Users folder and Health | but got %s", buf.String()))
+	}
+	create := written.Item[0].Item[0].Request
+	if create.Method != POST || create.URL.Raw != "http://example.com/api/users?verbose=1" ||
+		strings.Join(create.URL.Path, "/") != "api/users" || create.URL.Query[0].Key != "verbose" ||
+		create.Body.Mode != "raw" || create.Body.Raw != `{"name":"gopher"}` || create.Body.Options.Raw.Language != "json" {
+		t.Error(fmt.Sprintf("Expected a JSON request | but got %+v %+v", create, create.Body))
+	}
+	for _, header := range create.Header {
+		if header.Key == "Authorization" && header.Value != "[REDACTED]" {
+			t.Error(fmt.Sprintf("Expected a redacted Authorization header | but got %s", header.Value))
+		}
+	}
+	if login := written.Item[0].Item[1].Request; login.Body.Mode != "urlencoded" || len(login.Body.URLEncoded) != 2 || login.Body.URLEncoded[0].Value != "true" {
+		t.Error(fmt.Sprintf("Expected an urlencoded body | but got %+v", login.Body))
+	}
+
+	// the collection reads back into the same requests
+	var got []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		got = append(got, fmt.Sprintf("%s %s %s", r.Method, r.URL.RequestURI(), body))
+	}))
+	defer ts.Close()
+	requests, err := written.Requests(NewClient(""), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, request := range requests {
+		request.Agent.Url = strings.Replace(request.Agent.Url, "http://example.com", ts.URL, 1)
+		request.Agent.End()
+	}
+	expected := `POST /api/users?verbose=1 {"name":"gopher"},POST /api/login remember=true&user=gopher,GET /api/health `
+	if strings.Join(got, ",") != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, strings.Join(got, ",")))
+	}
+}
+
+func TestPostmanURL(t *testing.T) {
+	var u PostmanURL
+	json.Unmarshal([]byte(`{"protocol": "https", "host": "example.com", "port": "8443", "path": ["a", "b"],
+	  "query": [{"key": "q", "value": "1"}, {"key": "off", "disabled": true}]}`), &u)
+	if expected := "https://example.com:8443/a/b?q=1"; u.String() != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, u.String()))
+	}
+	var kv PostmanKeyValue
+	json.Unmarshal([]byte(`{"key": "count", "value": 3}`), &kv)
+	if kv.Value != "3" {
+		t.Error(fmt.Sprintf("Expected 3 | but got %s", kv.Value))
+	}
+}
@@ -0,0 +1,216 @@
+// Package promtext records gorequest metrics and exposes them in the Prometheus text
+// format, without depending on the Prometheus client library:
+//
+//	collector := promtext.NewCollector()
+//	http.Handle("/metrics", collector)
+//
+//	gorequest.New().SetMetrics(collector).Get("https://example.com").End()
+//
+// It exposes the following metrics, labelled by method, host and route, and by
+// status_class except for the in-flight gauge:
+//
+//	gorequest_requests_total             counter
+//	gorequest_request_duration_seconds   histogram
+//	gorequest_requests_in_flight         gauge
+//	gorequest_retries_total              counter
+//
+// It is a standalone exposition, served on its own endpoint: a Collector is not a
+// prometheus.Collector, and can't be registered with a prometheus.Registerer.
+package promtext
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+)
+
+// DefaultBuckets are the upper bounds in seconds of the duration histogram buckets,
+// the same as the default of the Prometheus client library.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// A Collector is a gorequest.Metrics keeping the metrics in memory. It is safe for
+// concurrent use, and serves the metrics over HTTP.
+type Collector struct {
+	buckets []float64
+
+	mu        sync.Mutex
+	requests  map[gorequest.MetricLabels]uint64
+	durations map[gorequest.MetricLabels]*histogram
+	inFlight  map[gorequest.MetricLabels]int64
+	retries   map[gorequest.MetricLabels]uint64
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewCollector returns a Collector with the given duration buckets, or DefaultBuckets if none.
+func NewCollector(buckets ...float64) *Collector {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Collector{
+		buckets:   buckets,
+		requests:  make(map[gorequest.MetricLabels]uint64),
+		durations: make(map[gorequest.MetricLabels]*histogram),
+		inFlight:  make(map[gorequest.MetricLabels]int64),
+		retries:   make(map[gorequest.MetricLabels]uint64),
+	}
+}
+
+// RequestStarted implements gorequest.Metrics.
+func (c *Collector) RequestStarted(labels gorequest.MetricLabels) {
+	labels.StatusClass = ""
+	c.mu.Lock()
+	c.inFlight[labels]++
+	c.mu.Unlock()
+}
+
+// RequestRetried implements gorequest.Metrics.
+func (c *Collector) RequestRetried(labels gorequest.MetricLabels) {
+	c.mu.Lock()
+	c.retries[labels]++
+	c.mu.Unlock()
+}
+
+// RequestFinished implements gorequest.Metrics.
+func (c *Collector) RequestFinished(labels gorequest.MetricLabels, duration time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.requests[labels]++
+	h, ok := c.durations[labels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(c.buckets))}
+		c.durations[labels] = h
+	}
+	seconds := duration.Seconds()
+	if i := sort.SearchFloat64s(c.buckets, seconds); i < len(c.buckets) {
+		h.counts[i]++
+	}
+	h.sum += seconds
+	h.count++
+
+	labels.StatusClass = ""
+	c.inFlight[labels]--
+}
+
+// ServeHTTP writes the metrics in the Prometheus text format.
+func (c *Collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	c.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format to w.
+func (c *Collector) WriteTo(w io.Writer) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	writeHeader(cw, "gorequest_requests_total", "counter", "Requests sent, retries excluded.")
+	for _, labels := range sortedLabels(c.requests) {
+		fmt.Fprintf(cw, "gorequest_requests_total{%s} %d\n", formatLabels(labels, true), c.requests[labels])
+	}
+
+	writeHeader(cw, "gorequest_request_duration_seconds", "histogram", "Duration of requests, retries included.")
+	for _, labels := range sortedLabels(c.durations) {
+		h := c.durations[labels]
+		prefix := formatLabels(labels, true)
+		var cumulative uint64
+		for i, upper := range c.buckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(cw, "gorequest_request_duration_seconds_bucket{%s,le=%q} %d\n", prefix, formatFloat(upper), cumulative)
+		}
+		fmt.Fprintf(cw, "gorequest_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", prefix, h.count)
+		fmt.Fprintf(cw, "gorequest_request_duration_seconds_sum{%s} %s\n", prefix, formatFloat(h.sum))
+		fmt.Fprintf(cw, "gorequest_request_duration_seconds_count{%s} %d\n", prefix, h.count)
+	}
+
+	writeHeader(cw, "gorequest_requests_in_flight", "gauge", "Requests in progress.")
+	for _, labels := range sortedLabels(c.inFlight) {
+		fmt.Fprintf(cw, "gorequest_requests_in_flight{%s} %d\n", formatLabels(labels, false), c.inFlight[labels])
+	}
+
+	writeHeader(cw, "gorequest_retries_total", "counter", "Retries, labelled by the status class of the failed attempt.")
+	for _, labels := range sortedLabels(c.retries) {
+		fmt.Fprintf(cw, "gorequest_retries_total{%s} %d\n", formatLabels(labels, true), c.retries[labels])
+	}
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func writeHeader(w io.Writer, name, kind, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sortedLabels returns the keys of m in a stable order.
+func sortedLabels[V any](m map[gorequest.MetricLabels]V) []gorequest.MetricLabels {
+	keys := make([]gorequest.MetricLabels, 0, len(m))
+	for labels := range m {
+		keys = append(keys, labels)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.Method != b.Method {
+			return a.Method < b.Method
+		}
+		if a.Host != b.Host {
+			return a.Host < b.Host
+		}
+		if a.Route != b.Route {
+			return a.Route < b.Route
+		}
+		return a.StatusClass < b.StatusClass
+	})
+	return keys
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels gorequest.MetricLabels, withStatus bool) string {
+	s := fmt.Sprintf(`method="%s",host="%s",route="%s"`,
+		labelEscaper.Replace(labels.Method), labelEscaper.Replace(labels.Host), labelEscaper.Replace(labels.Route))
+	if withStatus {
+		s += fmt.Sprintf(`,status_class="%s"`, labelEscaper.Replace(labels.StatusClass))
+	}
+	return s
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// A countingWriter keeps the first error and the number of bytes written.
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
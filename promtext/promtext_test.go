@@ -0,0 +1,55 @@
+package promtext
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/parnurzeal/gorequest"
+)
+
+func TestCollector(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer ts.Close()
+	host := strings.TrimPrefix(ts.URL, "http://")
+
+	collector := NewCollector(0.5, 0.1)
+	client := gorequest.NewClient(ts.URL).SetMetrics(collector)
+	client.Get("/users/{id}").PathParam("id", 1).Retry(1, 0, http.StatusBadGateway).End()
+	client.Get("/users/{id}").PathParam("id", 2).End()
+	collector.RequestFinished(gorequest.MetricLabels{Method: "GET", Host: `a"b`, StatusClass: "error"}, time.Second)
+
+	rec := httptest.NewRecorder()
+	collector.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+
+	labels := fmt.Sprintf(`method="GET",host="%s",route="/users/{id}"`, host)
+	for _, expected := range []string{
+		"# TYPE gorequest_requests_total counter\n",
+		"# TYPE gorequest_request_duration_seconds histogram\n",
+		fmt.Sprintf("gorequest_requests_total{%s,status_class=\"2xx\"} 2\n", labels),
+		fmt.Sprintf("gorequest_request_duration_seconds_bucket{%s,status_class=\"2xx\",le=\"0.1\"} 2\n", labels),
+		fmt.Sprintf("gorequest_request_duration_seconds_bucket{%s,status_class=\"2xx\",le=\"+Inf\"} 2\n", labels),
+		fmt.Sprintf("gorequest_request_duration_seconds_count{%s,status_class=\"2xx\"} 2\n", labels),
+		fmt.Sprintf("gorequest_requests_in_flight{%s} 0\n", labels),
+		fmt.Sprintf("gorequest_retries_total{%s,status_class=\"5xx\"} 1\n", labels),
+		`gorequest_request_duration_seconds_bucket{method="GET",host="a\"b",route="",status_class="error",le="0.5"} 0` + "\n",
+		`gorequest_request_duration_seconds_bucket{method="GET",host="a\"b",route="",status_class="error",le="+Inf"} 1` + "\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Error(fmt.Sprintf("Expected %q in output | but got\n%s", expected, out))
+		}
+	}
+	if !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain; version=0.0.4") {
+		t.Error("Expected Prometheus text format content type")
+	}
+}
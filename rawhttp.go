@@ -0,0 +1,118 @@
+package gorequest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// FromRawHTTP returns a SuperAgent sending the raw HTTP/1.1 request read from r: a request
+// line, headers, a blank line and the body:
+//
+//	request, err := gorequest.FromRawHTTP(strings.NewReader("POST /users HTTP/1.1\n" +
+//	  "Host: api.example.com\n" +
+//	  "Content-Type: application/json\n" +
+//	  "\n" +
+//	  `{"name":"gopher"}`))
+//
+// Lines may end with CRLF or LF. Without Content-Length nor Transfer-Encoding header, the
+// body is the rest of r. The request is sent over http, unless the request line has an
+// absolute url such as "GET https://api.example.com/users HTTP/1.1". The body is sent
+// verbatim, as text/plain unless the request has a Content-Type header.
+func FromRawHTTP(r io.Reader) (*SuperAgent, error) {
+	reader := bufio.NewReader(r)
+	// skip the blank lines before the request line
+	for {
+		line, err := reader.Peek(1)
+		if err != nil || (line[0] != '\r' && line[0] != '\n') {
+			break
+		}
+		reader.ReadByte()
+	}
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "gorequest: invalid raw HTTP request")
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "gorequest: invalid raw HTTP request body")
+	}
+	if req.ContentLength <= 0 && len(req.TransferEncoding) == 0 {
+		if body, err = ioutil.ReadAll(reader); err != nil {
+			return nil, err
+		}
+	}
+
+	target := req.URL.String()
+	if !req.URL.IsAbs() {
+		if req.Host == "" {
+			return nil, errors.New("gorequest: raw HTTP request without Host header nor absolute url")
+		}
+		target = "http://" + req.Host + req.URL.RequestURI()
+	}
+	s := New().CustomMethod(req.Method, target)
+	for name, values := range req.Header {
+		if name == "Content-Length" || name == "Transfer-Encoding" {
+			continue
+		}
+		for _, value := range values {
+			s.AppendHeader(name, value)
+		}
+	}
+	if len(body) != 0 {
+		s.Type(TypeText).SendString(string(body))
+	}
+	return s, nil
+}
+
+// AsRawHTTP returns the raw HTTP/1.1 request End would send, made by MakeRequest, with a
+// Content-Length header and sorted headers so that it can be diffed. Secrets are redacted,
+// see SetRedaction. FromRawHTTP reads it back: the request line holds the path of http
+// requests, and the absolute url of https ones, "GET https://example.com/x HTTP/1.1".
+func (s *SuperAgent) AsRawHTTP() (string, error) {
+	if len(s.Errors) != 0 {
+		return "", s.Errors[0]
+	}
+	s.resolveTargetType()
+	req, err := s.MakeRequest()
+	if err != nil {
+		return "", err
+	}
+	r, err := s.redaction().redactRequest(req)
+	if err != nil {
+		return "", err
+	}
+	body, err := readBody(&r.Body)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	// the origin form "/path" stands for http, so other schemes get the absolute form
+	target := r.URL.RequestURI()
+	if r.URL.Scheme != "http" {
+		target = r.URL.Scheme + "://" + r.URL.Host + target
+	}
+	fmt.Fprintf(&out, "%s %s HTTP/1.1\r\nHost: %s\r\n", r.Method, target, host)
+	header := r.Header.Clone()
+	header.Del("Host")
+	if len(body) != 0 {
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+	}
+	if err := header.Write(&out); err != nil {
+		return "", err
+	}
+	out.WriteString("\r\n")
+	out.Write(body)
+	return out.String(), nil
+}
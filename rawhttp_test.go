@@ -0,0 +1,91 @@
+package gorequest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromRawHTTP(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		fmt.Fprintf(w, "%s %s %s %s %s", r.Method, r.URL.RequestURI(), r.Header.Get("Content-Type"), r.Header.Values("X-Tag"), body)
+	}))
+	defer ts.Close()
+
+	raw := "\nPOST /users?page=1 HTTP/1.1\n" +
+		"Host: " + strings.TrimPrefix(ts.URL, "http://") + "\n" +
+		"Content-Type: application/json\n" +
+		"X-Tag: a\n" +
+		"X-Tag: b\n" +
+		"\n" +
+		`{"name": "gopher"}`
+	request, err := FromRawHTTP(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, errs := request.End()
+	if expected := `POST /users?page=1 application/json [a b] {"name": "gopher"}`; errs != nil || body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s, %v", expected, body, errs))
+	}
+
+	raw = "PUT " + ts.URL + "/chunked HTTP/1.1\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\nignored"
+	request, err = FromRawHTTP(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, body, _ = request.End()
+	if expected := "PUT /chunked text/plain [] hello"; body != expected {
+		t.Error(fmt.Sprintf("Expected %s | but got %s", expected, body))
+	}
+
+	for _, raw := range []string{"", "GET / HTTP/1.1\r\n\r\n", "not http"} {
+		if _, err := FromRawHTTP(strings.NewReader(raw)); err == nil {
+			t.Error(fmt.Sprintf("Expected an error for %q | but got none", raw))
+		}
+	}
+}
+
+func TestAsRawHTTP(t *testing.T) {
+	raw, err := New().
+		Post("https://api.example.com/users").
+		Query("page=1").
+		Set("X-Api-Key", "secret").
+		Send(`{"name":"gopher"}`).
+		AsRawHTTP()
+	expected := "POST https://api.example.com/users?page=1 HTTP/1.1\r\n" +
+		"Host: api.example.com\r\n" +
+		"Content-Length: 17\r\n" +
+		"Content-Type: application/json\r\n" +
+		"X-Api-Key: [REDACTED]\r\n" +
+		"\r\n" +
+		`{"name":"gopher"}`
+	if err != nil || raw != expected {
+		t.Error(fmt.Sprintf("Expected %q | but got %q, %v", expected, raw, err))
+	}
+
+	// round trip
+	request, err := FromRawHTTP(strings.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Url != "https://api.example.com/users?page=1" {
+		t.Error(fmt.Sprintf("Expected the https url back | but got %s", request.Url))
+	}
+	again, _ := request.SetRedaction(RedactionPolicy{}).AsRawHTTP()
+	if again != raw {
+		t.Error(fmt.Sprintf("Expected the same raw request %q | but got %q", raw, again))
+	}
+
+	// http requests keep the origin form
+	raw, _ = New().Get("http://api.example.com/users").AsRawHTTP()
+	if expected := "GET /users HTTP/1.1\r\nHost: api.example.com\r\n\r\n"; raw != expected {
+		t.Error(fmt.Sprintf("Expected %q | but got %q", expected, raw))
+	}
+	if request, _ = FromRawHTTP(strings.NewReader(raw)); request.Url != "http://api.example.com/users" {
+		t.Error(fmt.Sprintf("Expected the http url back | but got %s", request.Url))
+	}
+}
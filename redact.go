@@ -0,0 +1,298 @@
+package gorequest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"moul.io/http2curl/v2"
+)
+
+// Redacted replaces the redacted values.
+const Redacted = "[REDACTED]"
+
+// A RedactionPolicy lists the secrets hidden from the debug output, the curl commands and
+// the exports of requests and responses, see SetRedaction. Names are case-insensitive.
+type RedactionPolicy struct {
+	// Headers are the names of the headers to redact.
+	Headers []string
+	// QueryParams are the names of the query parameters, and urlencoded and multipart form
+	// fields, to redact. Files of multipart forms are kept.
+	QueryParams []string
+	// JSONPaths are the dot-separated paths of the JSON body values to redact.
+	// A "*" segment matches any key or index, a "**" segment matches any number of them,
+	// so "**.password" redacts every password field.
+	JSONPaths []string
+	// URLPasswords redacts the passwords of urls.
+	URLPasswords bool
+}
+
+// DefaultRedactionPolicy is the policy used unless SetRedaction is called.
+var DefaultRedactionPolicy = RedactionPolicy{
+	Headers: []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token"},
+	QueryParams: []string{"access_token", "refresh_token", "id_token", "token", "api_key", "apikey",
+		"password", "secret", "client_secret"},
+	JSONPaths: []string{"**.access_token", "**.refresh_token", "**.id_token", "**.token", "**.api_key",
+		"**.password", "**.secret", "**.client_secret"},
+	URLPasswords: true,
+}
+
+// SetRedaction sets the secrets hidden from SetDebug, SetCurlCommand, AsCurlCommand and
+// the other outputs describing a request. DefaultRedactionPolicy is used until it is called;
+// pass an empty RedactionPolicy to print requests verbatim:
+//
+//	policy := gorequest.DefaultRedactionPolicy
+//	policy.Headers = append(policy.Headers, "X-Signature")
+//	gorequest.New().SetRedaction(policy).SetDebug(true)
+func (s *SuperAgent) SetRedaction(policy RedactionPolicy) *SuperAgent {
+	s.redactionPolicy = &policy
+	return s
+}
+
+// SetRedaction returns a Client with a redaction policy, see SuperAgent.SetRedaction.
+func (c *Client) SetRedaction(policy RedactionPolicy) *Client {
+	return c.with(func(s *SuperAgent) { s.SetRedaction(policy) })
+}
+
+// redaction returns the policy of the SuperAgent.
+func (s *SuperAgent) redaction() *RedactionPolicy {
+	if s.redactionPolicy == nil {
+		return &DefaultRedactionPolicy
+	}
+	return s.redactionPolicy
+}
+
+// redactRequest returns a copy of req without secrets. The body of req is read, and
+// replaced by an equal one.
+func (p *RedactionPolicy) redactRequest(req *http.Request) (*http.Request, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Header = p.redactHeader(req.Header)
+	r.URL = p.redactURL(req.URL)
+	r.Host = req.Host
+	if body != nil {
+		body = p.redactBody(req.Header.Get("Content-Type"), body)
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.GetBody = nil
+	}
+	return r, nil
+}
+
+// redactResponse returns a copy of resp without secrets, with body as its body.
+func (p *RedactionPolicy) redactResponse(resp *http.Response, body []byte) *http.Response {
+	r := *resp
+	r.Header = p.redactHeader(resp.Header)
+	body = p.redactBody(resp.Header.Get("Content-Type"), body)
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if r.ContentLength >= 0 {
+		r.ContentLength = int64(len(body))
+	}
+	return &r
+}
+
+// curlCommand returns the curl command of req without secrets.
+func (p *RedactionPolicy) curlCommand(req *http.Request) (*http2curl.CurlCommand, error) {
+	r, err := p.redactRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return http2curl.GetCurlCommand(r)
+}
+
+// readBody reads *body and replaces it with an equal one. It returns nil for no body.
+func readBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	data, err := ioutil.ReadAll(*body)
+	(*body).Close()
+	*body = ioutil.NopCloser(bytes.NewReader(data))
+	return data, err
+}
+
+func (p *RedactionPolicy) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range p.Headers {
+		name = http.CanonicalHeaderKey(name)
+		for i := range redacted[name] {
+			redacted[name][i] = Redacted
+		}
+	}
+	return redacted
+}
+
+// redactURL returns a copy of u without password nor secret query parameters. The
+// password is replaced by Redacted, which urls escape as %5BREDACTED%5D.
+func (p *RedactionPolicy) redactURL(u *url.URL) *url.URL {
+	r := *u
+	if _, ok := u.User.Password(); ok && p.URLPasswords {
+		r.User = url.UserPassword(u.User.Username(), Redacted)
+	}
+	r.RawQuery = p.redactQuery(u.RawQuery)
+	return &r
+}
+
+// redactRawURL returns rawURL without secrets, or Redacted if it can't be parsed.
+func (p *RedactionPolicy) redactRawURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return Redacted
+	}
+	return p.redactURL(u).String()
+}
+
+// redactError returns err with the url of an *url.Error redacted, as the ones of the
+// http.Client are, or err itself.
+func (p *RedactionPolicy) redactError(err error) error {
+	urlErr, ok := err.(*url.Error)
+	if !ok {
+		return err
+	}
+	redacted := *urlErr
+	redacted.URL = p.redactRawURL(urlErr.URL)
+	return &redacted
+}
+
+// redactQuery redacts the secret parameters of an urlencoded query, keeping its order.
+func (p *RedactionPolicy) redactQuery(query string) string {
+	if query == "" || len(p.QueryParams) == 0 {
+		return query
+	}
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		rawKey := pair
+		if j := strings.IndexByte(pair, '='); j >= 0 {
+			rawKey = pair[:j]
+		}
+		key, err := url.QueryUnescape(rawKey)
+		if err != nil {
+			key = rawKey
+		}
+		if containsFold(p.QueryParams, key) {
+			pairs[i] = rawKey + "=" + Redacted
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// redactBody redacts a JSON, urlencoded or multipart body. Other bodies are returned unchanged.
+func (p *RedactionPolicy) redactBody(contentType string, body []byte) []byte {
+	mediaType, params, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "application/x-www-form-urlencoded":
+		return []byte(p.redactQuery(string(body)))
+	case strings.HasPrefix(mediaType, "multipart/") && params["boundary"] != "":
+		if len(p.QueryParams) == 0 {
+			return body
+		}
+		if redacted, err := p.redactMultipart(body, params["boundary"]); err == nil {
+			return redacted
+		}
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		if len(p.JSONPaths) == 0 {
+			return body
+		}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var v interface{}
+		if err := decoder.Decode(&v); err != nil {
+			return body
+		}
+		redacted := false
+		for _, path := range p.JSONPaths {
+			v = redactJSONPath(v, strings.Split(path, "."), &redacted)
+		}
+		if !redacted {
+			return body
+		}
+		if data, err := json.Marshal(v); err == nil {
+			return data
+		}
+	}
+	return body
+}
+
+// redactMultipart redacts the secret fields of a multipart body, keeping its parts and
+// their headers as they are.
+func (p *RedactionPolicy) redactMultipart(body []byte, boundary string) ([]byte, error) {
+	reader := multipart.NewReader(bytes.NewReader(body), boundary)
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		w, err := writer.CreatePart(part.Header)
+		if err != nil {
+			return nil, err
+		}
+		if part.FileName() == "" && containsFold(p.QueryParams, part.FormName()) {
+			_, err = io.WriteString(w, Redacted)
+		} else {
+			_, err = io.Copy(w, part)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// redactJSONPath replaces the values of v matching path by Redacted.
+func redactJSONPath(v interface{}, path []string, redacted *bool) interface{} {
+	if len(path) == 0 {
+		*redacted = true
+		return Redacted
+	}
+	segment, rest := path[0], path[1:]
+	if segment == "**" {
+		// match zero levels, then one more level keeping "**"
+		v = redactJSONPath(v, rest, redacted)
+		rest = path
+	}
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, child := range value {
+			if segment == "*" || segment == "**" || strings.EqualFold(segment, key) {
+				value[key] = redactJSONPath(child, rest, redacted)
+			}
+		}
+	case []interface{}:
+		for i, child := range value {
+			if segment == "*" || segment == "**" || segment == strconv.Itoa(i) {
+				value[i] = redactJSONPath(child, rest, redacted)
+			}
+		}
+	}
+	return v
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
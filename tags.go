@@ -0,0 +1,298 @@
+package gorequest
+
+import (
+	"encoding"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Struct tags we support for binding a struct to a request.
+const (
+	TagURL    = "url"    // query string, see Query
+	TagForm   = "form"   // form body, see SendStruct
+	TagHeader = "header" // request header, see Set
+	TagPath   = "path"   // path parameter, see PathParam
+)
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// A taggedField is a struct field found by structTagFields.
+// values has one element unless isArray is set.
+type taggedField struct {
+	name        string
+	values      []string
+	isArray     bool
+	arrayFormat string
+}
+
+// BindStruct reads the `path`, `header`, `url` and `form` tags of a struct and applies
+// them to the request, so that one struct can describe the whole call:
+//
+//	type GetRepos struct {
+//	  User    string    `path:"user"`
+//	  Token   string    `header:"Authorization"`
+//	  Since   time.Time `url:"since,omitempty" layout:"2006-01-02"`
+//	  PerPage int       `url:"per_page,omitempty"`
+//	}
+//	gorequest.New().
+//	  Get("https://api.github.com/users/{user}/repos").
+//	  BindStruct(GetRepos{User: "parnurzeal", PerPage: 50}).
+//	  End()
+//
+// The tag value is the name, optionally followed by options separated by commas:
+//
+//	"-" skips the field
+//	"omitempty" skips the field if it has a zero value
+//	"repeat", "brackets", "comma" or "pipe" set the array format of a slice (see SetArrayFormat)
+//	"unix" and "unixmilli" send a time.Time as a Unix timestamp
+//	"seconds" and "millis" send a time.Duration as a number
+//
+// A time.Time is sent as RFC 3339 unless the field also has a `layout` tag, a
+// time.Duration is sent as its String() and encoding.TextMarshaler values use MarshalText.
+// Embedded structs without a tag are flattened.
+func (s *SuperAgent) BindStruct(content interface{}) *SuperAgent {
+	v := reflect.ValueOf(content)
+	for _, tag := range []string{TagPath, TagHeader, TagURL, TagForm} {
+		fields, err := structTagFields(v, tag)
+		if err != nil {
+			s.Errors = append(s.Errors, err)
+			return s
+		}
+		switch tag {
+		case TagPath:
+			for _, f := range fields {
+				if f.isArray {
+					s.PathParam(f.name, f.values)
+				} else {
+					s.PathParam(f.name, f.values[0])
+				}
+			}
+		case TagHeader:
+			for _, f := range fields {
+				s.Header.Del(f.name)
+				for _, value := range f.values {
+					s.Header.Add(f.name, value)
+				}
+			}
+		case TagURL:
+			s.addTaggedFields(s.QueryData, fields)
+		case TagForm:
+			if len(fields) != 0 {
+				s.addTaggedFields(s.FormData, fields)
+				s.TargetType = TypeForm
+			}
+		}
+	}
+	return s
+}
+
+func (s *SuperAgent) queryTagged(content interface{}) *SuperAgent {
+	fields, err := structTagFields(reflect.ValueOf(content), TagURL)
+	if err != nil {
+		s.Errors = append(s.Errors, err)
+		return s
+	}
+	s.addTaggedFields(s.QueryData, fields)
+	return s
+}
+
+func (s *SuperAgent) sendTagged(content interface{}) *SuperAgent {
+	fields, err := structTagFields(reflect.ValueOf(content), TagForm)
+	if err != nil {
+		s.Errors = append(s.Errors, err)
+		return s
+	}
+	s.addTaggedFields(s.FormData, fields)
+	s.TargetType = TypeForm
+	return s
+}
+
+func (s *SuperAgent) addTaggedFields(values url.Values, fields []taggedField) {
+	for _, f := range fields {
+		if !f.isArray {
+			values.Add(f.name, f.values[0])
+			continue
+		}
+		arrayFormat := f.arrayFormat
+		if arrayFormat == "" {
+			arrayFormat = s.arrayFormat()
+		}
+		addArrayValues(values, f.name, f.values, arrayFormat)
+	}
+}
+
+// hasStructTag reports whether v is a struct, or a pointer to one, with at least one field carrying tag.
+func hasStructTag(v reflect.Value, tag string) bool {
+	if !v.IsValid() {
+		return false
+	}
+	return structTypeHasTag(v.Type(), tag)
+}
+
+func structTypeHasTag(t reflect.Type, tag string) bool {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == timeType {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+		if field.Anonymous && structTypeHasTag(field.Type, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// structTagFields returns the fields of the struct v carrying tag, in field order.
+func structTagFields(v reflect.Value, tag string) ([]taggedField, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, errors.New("Struct tags: expected a struct but got " + v.Kind().String())
+	}
+
+	var fields []taggedField
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tagValue, ok := sf.Tag.Lookup(tag)
+		if !ok {
+			if sf.Anonymous {
+				embedded, err := structTagFields(v.Field(i), tag)
+				if err != nil {
+					return nil, err
+				}
+				fields = append(fields, embedded...)
+			}
+			continue
+		}
+		if sf.PkgPath != "" || tagValue == "-" {
+			continue
+		}
+
+		options := strings.Split(tagValue, ",")
+		name := options[0]
+		if name == "" {
+			name = sf.Name
+		}
+		fv := v.Field(i)
+		if hasOption(options, "omitempty") && fv.IsZero() {
+			continue
+		}
+
+		f := taggedField{name: name}
+		for _, format := range []string{ArrayFormatRepeat, ArrayFormatBrackets, ArrayFormatComma, ArrayFormatPipe} {
+			if hasOption(options, format) {
+				f.arrayFormat = format
+			}
+		}
+		layout := sf.Tag.Get("layout")
+
+		if value, ok, err := formatTagValue(fv, options, layout); err != nil {
+			return nil, errors.Wrap(err, "Struct tags: field "+sf.Name)
+		} else if ok {
+			f.values = []string{value}
+		} else if elem := reflect.Indirect(fv); elem.Kind() == reflect.Slice || elem.Kind() == reflect.Array {
+			f.isArray = true
+			for j := 0; j < elem.Len(); j++ {
+				value, ok, err := formatTagValue(elem.Index(j), options, layout)
+				if err != nil {
+					return nil, errors.Wrap(err, "Struct tags: field "+sf.Name)
+				}
+				if !ok {
+					return nil, errors.New("Struct tags: unsupported element type " + elem.Type().Elem().String() + " for field " + sf.Name)
+				}
+				f.values = append(f.values, value)
+			}
+		} else if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			continue
+		} else {
+			return nil, errors.New("Struct tags: unsupported type " + fv.Type().String() + " for field " + sf.Name)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// formatTagValue returns the string form of a single field value. It returns false
+// if v is a nil pointer or not a single value, e.g. a slice.
+func formatTagValue(v reflect.Value, options []string, layout string) (string, bool, error) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", false, nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Type() {
+	case timeType:
+		t := v.Interface().(time.Time)
+		switch {
+		case hasOption(options, "unix"):
+			return strconv.FormatInt(t.Unix(), 10), true, nil
+		case hasOption(options, "unixmilli"):
+			return strconv.FormatInt(t.UnixNano()/int64(time.Millisecond), 10), true, nil
+		case layout != "":
+			return t.Format(layout), true, nil
+		}
+		return t.Format(time.RFC3339), true, nil
+	case durationType:
+		d := time.Duration(v.Int())
+		switch {
+		case hasOption(options, "seconds"):
+			return strconv.FormatFloat(d.Seconds(), 'f', -1, 64), true, nil
+		case hasOption(options, "millis"):
+			return strconv.FormatInt(int64(d/time.Millisecond), 10), true, nil
+		}
+		return d.String(), true, nil
+	}
+
+	if marshaler, ok := textMarshaler(v); ok {
+		text, err := marshaler.MarshalText()
+		if err != nil {
+			return "", false, err
+		}
+		return string(text), true, nil
+	}
+	value, ok := formatScalar(v)
+	return value, ok, nil
+}
+
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if v.Type().Implements(textMarshalerType) {
+		return v.Interface().(encoding.TextMarshaler), true
+	}
+	if reflect.PtrTo(v.Type()).Implements(textMarshalerType) {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		return ptr.Interface().(encoding.TextMarshaler), true
+	}
+	return nil, false
+}
+
+func hasOption(options []string, option string) bool {
+	for _, o := range options[1:] {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
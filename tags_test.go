@@ -0,0 +1,136 @@
+package gorequest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type tagsSearch struct {
+	Query    string        `url:"q"`
+	Page     int           `url:"page,omitempty"`
+	Since    time.Time     `url:"since" layout:"2006-01-02"`
+	Until    time.Time     `url:"until,unix"`
+	Wait     time.Duration `url:"wait"`
+	Timeout  time.Duration `url:"timeout,seconds"`
+	IP       net.IP        `url:"ip"`
+	Tags     []string      `url:"tags,comma"`
+	Ids      []int         `url:"ids"`
+	Optional *string       `url:"optional"`
+	Skipped  string        `url:"-"`
+	Untagged string
+}
+
+func TestStructTagFields(t *testing.T) {
+	search := tagsSearch{
+		Query:   "gopher",
+		Since:   time.Date(2016, 8, 30, 12, 0, 0, 0, time.UTC),
+		Until:   time.Unix(1472558400, 0),
+		Wait:    90 * time.Second,
+		Timeout: 1500 * time.Millisecond,
+		IP:      net.ParseIP("127.0.0.1"),
+		Tags:    []string{"a", "b"},
+		Ids:     []int{1, 2},
+		Skipped: "skipped",
+	}
+	fields, err := structTagFields(reflect.ValueOf(search), TagURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []taggedField{
+		{name: "q", values: []string{"gopher"}},
+		{name: "since", values: []string{"2016-08-30"}},
+		{name: "until", values: []string{"1472558400"}},
+		{name: "wait", values: []string{"1m30s"}},
+		{name: "timeout", values: []string{"1.5"}},
+		{name: "ip", values: []string{"127.0.0.1"}},
+		{name: "tags", values: []string{"a", "b"}, isArray: true, arrayFormat: ArrayFormatComma},
+		{name: "ids", values: []string{"1", "2"}, isArray: true},
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Error(fmt.Sprintf("Expected fields %+v | but got %+v", want, fields))
+	}
+
+	if _, err := structTagFields(reflect.ValueOf(struct {
+		M map[string]string `url:"m"`
+	}{}), TagURL); err == nil {
+		t.Error("Expected error for unsupported map field")
+	}
+}
+
+func TestQueryStructTags(t *testing.T) {
+	var rawQuery string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rawQuery = r.URL.RawQuery
+	}))
+	defer ts.Close()
+
+	New().Get(ts.URL).
+		SetArrayFormat(ArrayFormatBrackets).
+		Query(&tagsSearch{Query: "gopher", Tags: []string{"a", "b"}, Ids: []int{1, 2}}).
+		End()
+	want := "ids%5B%5D=1&ids%5B%5D=2&ip=&q=gopher&since=0001-01-01&tags=a%2Cb&timeout=0&until=-62135596800&wait=0s"
+	if rawQuery != want {
+		t.Error(fmt.Sprintf("Expected query %q | but got %q", want, rawQuery))
+	}
+}
+
+func TestSendStructFormTags(t *testing.T) {
+	var contentType, body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentType = r.Header.Get("Content-Type")
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer ts.Close()
+
+	login := struct {
+		User     string `form:"user"`
+		Password string `form:"pass"`
+		Remember bool   `form:"remember,omitempty"`
+		Internal string `json:"internal"`
+	}{User: "gopher", Password: "secret"}
+	New().Post(ts.URL).Send(login).End()
+	if contentType != "application/x-www-form-urlencoded" {
+		t.Error(fmt.Sprintf("Expected Content-Type %q | but got %q", "application/x-www-form-urlencoded", contentType))
+	}
+	if body != "pass=secret&user=gopher" {
+		t.Error(fmt.Sprintf("Expected body %q | but got %q", "pass=secret&user=gopher", body))
+	}
+}
+
+func TestBindStruct(t *testing.T) {
+	type pagination struct {
+		PerPage int `url:"per_page"`
+	}
+	type getRepos struct {
+		User  string `path:"user"`
+		Token string `header:"Authorization"`
+		Sort  string `url:"sort,omitempty"`
+		pagination
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/users/a%2Fb/repos" {
+			t.Error(fmt.Sprintf("Expected path %q | but got %q", "/users/a%2Fb/repos", r.URL.EscapedPath()))
+		}
+		if r.Header.Get("Authorization") != "token abc" {
+			t.Error(fmt.Sprintf("Expected Authorization %q | but got %q", "token abc", r.Header.Get("Authorization")))
+		}
+		if r.URL.RawQuery != "per_page=50" {
+			t.Error(fmt.Sprintf("Expected query %q | but got %q", "per_page=50", r.URL.RawQuery))
+		}
+	}))
+	defer ts.Close()
+
+	_, _, errs := New().Get(ts.URL + "/users/{user}/repos").
+		BindStruct(getRepos{User: "a/b", Token: "token abc", pagination: pagination{PerPage: 50}}).
+		End()
+	if errs != nil {
+		t.Error(errs)
+	}
+}
@@ -0,0 +1,109 @@
+package gorequest
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ErrBodyIdleTimeout is returned when no data of the response body arrived during the
+// duration set by BodyIdleTimeout.
+var ErrBodyIdleTimeout = errors.New("gorequest: response body idle timeout exceeded")
+
+// DialTimeout limits the time to open a TCP connection, see net.Dialer.Timeout.
+// Like TLSClientConfig, it gives a clone a transport of its own.
+func (s *SuperAgent) DialTimeout(timeout time.Duration) *SuperAgent {
+	s.safeModifyTransport()
+	s.Transport.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	s.dialTimeout = timeout
+	return s
+}
+
+// TLSHandshakeTimeout limits the time of the TLS handshake, see http.Transport.TLSHandshakeTimeout.
+// Like TLSClientConfig, it gives a clone a transport of its own.
+func (s *SuperAgent) TLSHandshakeTimeout(timeout time.Duration) *SuperAgent {
+	s.safeModifyTransport()
+	s.Transport.TLSHandshakeTimeout = timeout
+	return s
+}
+
+// ResponseHeaderTimeout limits the time to wait for the response headers once the request
+// is written, see http.Transport.ResponseHeaderTimeout.
+// Like TLSClientConfig, it gives a clone a transport of its own.
+func (s *SuperAgent) ResponseHeaderTimeout(timeout time.Duration) *SuperAgent {
+	s.safeModifyTransport()
+	s.Transport.ResponseHeaderTimeout = timeout
+	return s
+}
+
+// BodyIdleTimeout limits the time between two reads of the response body. Unlike Timeout,
+// which covers the whole exchange, it lets long but healthy downloads finish while still
+// failing stalled ones with ErrBodyIdleTimeout:
+//
+//	gorequest.New().
+//	  DialTimeout(5 * time.Second).
+//	  ResponseHeaderTimeout(10 * time.Second).
+//	  BodyIdleTimeout(30 * time.Second).
+//	  Get("https://example.com/large-file").
+//	  EndBytes()
+func (s *SuperAgent) BodyIdleTimeout(timeout time.Duration) *SuperAgent {
+	s.bodyIdleTimeout = timeout
+	return s
+}
+
+// DialTimeout returns a Client with a dial timeout, see SuperAgent.DialTimeout.
+func (c *Client) DialTimeout(timeout time.Duration) *Client {
+	return c.with(func(s *SuperAgent) { s.DialTimeout(timeout) })
+}
+
+// TLSHandshakeTimeout returns a Client with a TLS handshake timeout, see SuperAgent.TLSHandshakeTimeout.
+func (c *Client) TLSHandshakeTimeout(timeout time.Duration) *Client {
+	return c.with(func(s *SuperAgent) { s.TLSHandshakeTimeout(timeout) })
+}
+
+// ResponseHeaderTimeout returns a Client with a response header timeout, see SuperAgent.ResponseHeaderTimeout.
+func (c *Client) ResponseHeaderTimeout(timeout time.Duration) *Client {
+	return c.with(func(s *SuperAgent) { s.ResponseHeaderTimeout(timeout) })
+}
+
+// BodyIdleTimeout returns a Client with a body idle timeout, see SuperAgent.BodyIdleTimeout.
+func (c *Client) BodyIdleTimeout(timeout time.Duration) *Client {
+	return c.with(func(s *SuperAgent) { s.BodyIdleTimeout(timeout) })
+}
+
+// An idleTimeoutBody cancels the request once no Read returned during timeout.
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	expired int32
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, timeout: timeout}
+	b.timer = time.AfterFunc(timeout, func() {
+		atomic.StoreInt32(&b.expired, 1)
+		cancel()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if atomic.LoadInt32(&b.expired) == 1 {
+		return n, ErrBodyIdleTimeout
+	}
+	if err == nil {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	return b.ReadCloser.Close()
+}
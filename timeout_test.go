@@ -0,0 +1,66 @@
+package gorequest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBodyIdleTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gap, _ := time.ParseDuration(r.URL.Query().Get("gap"))
+		for i := 0; i < 4; i++ {
+			w.Write([]byte("chunk"))
+			w.(http.Flusher).Flush()
+			time.Sleep(gap)
+		}
+	}))
+	defer ts.Close()
+
+	// slow but steady download takes longer than the idle timeout
+	_, body, errs := New().BodyIdleTimeout(150*time.Millisecond).Get(ts.URL).Param("gap", "50ms").End()
+	if errs != nil || body != strings.Repeat("chunk", 4) {
+		t.Error(fmt.Sprintf("Expected steady download to succeed | but got %q, %v", body, errs))
+	}
+
+	_, _, errs = New().BodyIdleTimeout(50*time.Millisecond).Get(ts.URL).Param("gap", "300ms").End()
+	if len(errs) != 1 || errs[0] != ErrBodyIdleTimeout {
+		t.Error(fmt.Sprintf("Expected ErrBodyIdleTimeout | but got %v", errs))
+	}
+}
+
+func TestResponseHeaderTimeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+	}))
+	defer ts.Close()
+
+	start := time.Now()
+	_, _, errs := New().ResponseHeaderTimeout(50 * time.Millisecond).Get(ts.URL).End()
+	if errs == nil || time.Since(start) > 150*time.Millisecond {
+		t.Error(fmt.Sprintf("Expected response header timeout | but got %v after %v", errs, time.Since(start)))
+	}
+}
+
+func TestTimeoutsOnClone(t *testing.T) {
+	base := New().TLSHandshakeTimeout(time.Second)
+	clone := base.Clone().DialTimeout(time.Second).ResponseHeaderTimeout(time.Second)
+	if base.Transport.DialContext != nil || base.Transport.ResponseHeaderTimeout != 0 {
+		t.Error("Expected timeouts on a clone not to change the base transport")
+	}
+	if clone.Transport.TLSHandshakeTimeout != time.Second || clone.Transport.DialContext == nil {
+		t.Error("Expected clone to keep the base timeouts and add its own")
+	}
+	if clone.Client == base.Client {
+		t.Error("Expected clone to get its own http.Client")
+	}
+
+	key1, ok1 := newTransportKey(clone.Transport, "", clone.dialTimeout)
+	key2, ok2 := newTransportKey(New().DialTimeout(2*time.Second).Transport, "", 2*time.Second)
+	if !ok1 || !ok2 || key1 == key2 {
+		t.Error("Expected dial timeouts to be part of the shared transport key")
+	}
+}
@@ -0,0 +1,160 @@
+package gorequest
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// A TraceInfo holds the timing breakdown of a request, see Trace.
+// Durations of steps which didn't happen, e.g. DNSLookup for a reused connection, are zero.
+type TraceInfo struct {
+	// DNSLookup is the time spent resolving the host.
+	DNSLookup time.Duration
+	// Connect is the time spent opening the TCP connection.
+	Connect time.Duration
+	// TLSHandshake is the time spent on the TLS handshake.
+	TLSHandshake time.Duration
+	// ServerProcessing is the time between writing the request and the first response byte.
+	ServerProcessing time.Duration
+	// TimeToFirstByte is the time between starting the request and the first response byte.
+	TimeToFirstByte time.Duration
+	// Total is the time between starting the request and reading the whole body.
+	Total time.Duration
+	// RemoteAddr is the address of the server, or of the proxy.
+	RemoteAddr string
+	// ConnReused tells whether the connection was reused from the pool, see ConnectionPool.
+	ConnReused bool
+	// ConnIdleTime is how long a reused connection had been idle.
+	ConnIdleTime time.Duration
+}
+
+func (t TraceInfo) String() string {
+	return fmt.Sprintf("dns=%v connect=%v tls=%v server=%v ttfb=%v total=%v remote=%s reused=%t",
+		t.DNSLookup, t.Connect, t.TLSHandshake, t.ServerProcessing, t.TimeToFirstByte, t.Total, t.RemoteAddr, t.ConnReused)
+}
+
+// Trace enables the timing breakdown of requests using net/http/httptrace. The timings
+// of a response are returned by ResponseTrace, and logged when debug mode is enabled:
+//
+//	resp, _, errs := gorequest.New().Trace().Get("https://example.com").End()
+//	if trace, ok := gorequest.ResponseTrace(resp); ok {
+//	  fmt.Println(trace.DNSLookup, trace.TimeToFirstByte, trace.ConnReused)
+//	}
+func (s *SuperAgent) Trace() *SuperAgent {
+	s.trace = true
+	return s
+}
+
+// Trace returns a Client tracing its requests, see SuperAgent.Trace.
+func (c *Client) Trace() *Client {
+	return c.with(func(s *SuperAgent) { s.Trace() })
+}
+
+// ResponseTrace returns the timings of a response sent with Trace enabled.
+func ResponseTrace(resp Response) (TraceInfo, bool) {
+	if resp == nil {
+		return TraceInfo{}, false
+	}
+	body, ok := resp.Body.(*tracedBody)
+	if !ok {
+		return TraceInfo{}, false
+	}
+	return body.info, true
+}
+
+// A requestTracer collects the httptrace events of one request.
+// Its hooks may be called from other goroutines than the one sending the request.
+type requestTracer struct {
+	mu                sync.Mutex
+	start             time.Time
+	dnsStart          time.Time
+	dnsDone           time.Time
+	connectStart      time.Time
+	connectDone       time.Time
+	tlsStart          time.Time
+	tlsDone           time.Time
+	wroteRequest      time.Time
+	firstResponseByte time.Time
+	gotConnAt         time.Time
+	gotConn           httptrace.GotConnInfo
+}
+
+func newRequestTracer() *requestTracer {
+	return &requestTracer{start: time.Now()}
+}
+
+// withTrace returns req with the hooks of t installed.
+func (t *requestTracer) withTrace(req *http.Request) *http.Request {
+	now := func(at *time.Time) {
+		t.mu.Lock()
+		*at = time.Now()
+		t.mu.Unlock()
+	}
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { now(&t.dnsStart) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { now(&t.dnsDone) },
+		ConnectStart: func(network, addr string) {
+			t.mu.Lock()
+			// with several addresses, keep the first attempt
+			if t.connectStart.IsZero() {
+				t.connectStart = time.Now()
+			}
+			t.mu.Unlock()
+		},
+		ConnectDone: func(network, addr string, err error) {
+			if err == nil {
+				now(&t.connectDone)
+			}
+		},
+		TLSHandshakeStart: func() { now(&t.tlsStart) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { now(&t.tlsDone) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			t.gotConnAt = time.Now()
+			t.gotConn = info
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { now(&t.wroteRequest) },
+		GotFirstResponseByte: func() { now(&t.firstResponseByte) },
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// done returns the timings once the body has been read.
+func (t *requestTracer) done() TraceInfo {
+	end := time.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	between := func(from, to time.Time) time.Duration {
+		if from.IsZero() || to.IsZero() {
+			return 0
+		}
+		return to.Sub(from)
+	}
+	info := TraceInfo{
+		DNSLookup:        between(t.dnsStart, t.dnsDone),
+		Connect:          between(t.connectStart, t.connectDone),
+		TLSHandshake:     between(t.tlsStart, t.tlsDone),
+		ServerProcessing: between(t.wroteRequest, t.firstResponseByte),
+		TimeToFirstByte:  between(t.start, t.firstResponseByte),
+		Total:            end.Sub(t.start),
+		ConnReused:       t.gotConn.Reused,
+		ConnIdleTime:     t.gotConn.IdleTime,
+	}
+	if t.gotConn.Conn != nil {
+		info.RemoteAddr = t.gotConn.Conn.RemoteAddr().String()
+	}
+	return info
+}
+
+// A tracedBody is a response body carrying the timings of its request, so that
+// ResponseTrace finds them without changing resp.Request.
+type tracedBody struct {
+	io.ReadCloser
+	info TraceInfo
+}
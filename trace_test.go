@@ -0,0 +1,58 @@
+package gorequest
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrace(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte("ok"))
+	}))
+	defer ts.Close()
+
+	resp, _, _ := New().Get(ts.URL).End()
+	if _, ok := ResponseTrace(resp); ok {
+		t.Error("Expected no trace without Trace")
+	}
+
+	var buf bytes.Buffer
+	request := New().ConnectionPool(DefaultPoolConfig).Trace().SetLogger(log.New(&buf, "", 0))
+	resp, _, errs := request.Get(ts.URL).End()
+	info, ok := ResponseTrace(resp)
+	if errs != nil || !ok {
+		t.Fatal(fmt.Sprintf("Expected a trace | but got %v, %v", ok, errs))
+	}
+	// the timings are kept with the body, which still reads, and resp.Request is the one sent
+	if body, _ := ioutil.ReadAll(resp.Body); string(body) != "ok" || resp.Request.URL.String() != ts.URL {
+		t.Error(fmt.Sprintf("Expected the body and request as sent | but got %q, %v", body, resp.Request.URL))
+	}
+	if info.ConnReused || info.RemoteAddr != ts.Listener.Addr().String() {
+		t.Error(fmt.Sprintf("Expected a new connection to %s | but got %+v", ts.Listener.Addr(), info))
+	}
+	if info.ServerProcessing < 10*time.Millisecond || info.TimeToFirstByte < info.ServerProcessing || info.Total < info.TimeToFirstByte {
+		t.Error(fmt.Sprintf("Expected consistent timings | but got %+v", info))
+	}
+	if buf.Len() != 0 {
+		t.Error(fmt.Sprintf("Expected no log without debug | but got %q", buf.String()))
+	}
+
+	// the trace is also passed to the callbacks
+	request.SetDebug(true).Get(ts.URL).End(func(resp Response, body string, errs []error) {
+		info, ok = ResponseTrace(resp)
+	})
+	if !ok || !info.ConnReused || info.Connect != 0 {
+		t.Error(fmt.Sprintf("Expected a reused connection | but got %+v", info))
+	}
+	if !strings.Contains(buf.String(), "HTTP Trace: dns=") {
+		t.Error(fmt.Sprintf("Expected trace in debug output | but got %q", buf.String()))
+	}
+}
@@ -0,0 +1,155 @@
+package gorequest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// A Tracer starts the spans of requests, see SetTracer. It is shaped after the
+// OpenTelemetry API so that an adapter is a few lines, without gorequest depending on it.
+type Tracer interface {
+	// Start starts a client span as a child of the span in ctx, if any, and returns a
+	// context holding the new span.
+	Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span)
+}
+
+// A Span is a span started by a Tracer.
+type Span interface {
+	SetAttributes(attrs ...Attribute)
+	// RecordError marks the span as failed.
+	RecordError(err error)
+	End()
+	// SpanContext returns the ids of the span, sent in the traceparent header.
+	SpanContext() SpanContext
+}
+
+// An Attribute is a key-value pair of a span. Values are of type string, int or bool.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// A SpanContext holds the W3C Trace Context of a span.
+type SpanContext struct {
+	TraceID    [16]byte
+	SpanID     [8]byte
+	Sampled    bool
+	TraceState string
+}
+
+// IsValid tells whether both ids are set.
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != [16]byte{} && sc.SpanID != [8]byte{}
+}
+
+// SetTracer makes every attempt of a request, retries included, run in a span started
+// by tracer. Spans follow the OpenTelemetry semantic conventions for HTTP clients, and
+// their context is sent to the server in the traceparent and tracestate headers:
+//
+//	gorequest.New().
+//	  SetTracer(tracer).
+//	  Context(ctx). // holding the parent span, if any
+//	  Get("https://example.com/users/{id}").
+//	  PathParam("id", 42).
+//	  End()
+//
+// The span is named after the method and the path template, here "GET /users/{id}".
+func (s *SuperAgent) SetTracer(tracer Tracer) *SuperAgent {
+	s.tracer = tracer
+	return s
+}
+
+// SetTracer returns a Client tracing its requests, see SuperAgent.SetTracer.
+func (c *Client) SetTracer(tracer Tracer) *Client {
+	return c.with(func(s *SuperAgent) { s.SetTracer(tracer) })
+}
+
+// startSpan starts the span of one attempt of req and injects its context in the headers.
+func (s *SuperAgent) startSpan(req *http.Request) (*http.Request, Span) {
+	name := req.Method
+	attrs := []Attribute{
+		{"http.request.method", req.Method},
+		{"url.full", s.redaction().redactURL(req.URL).String()},
+		{"server.address", req.URL.Hostname()},
+	}
+	if port := serverPort(req); port != 0 {
+		attrs = append(attrs, Attribute{"server.port", port})
+	}
+	if template := s.urlTemplate(); template != "" {
+		name += " " + template
+		attrs = append(attrs, Attribute{"url.template", template})
+	}
+	if s.Retryable.Attempt > 0 {
+		attrs = append(attrs, Attribute{"http.request.resend_count", s.Retryable.Attempt})
+	}
+
+	ctx, span := s.tracer.Start(req.Context(), name, attrs...)
+	req = req.WithContext(ctx)
+	if sc := span.SpanContext(); sc.IsValid() {
+		flags := 0
+		if sc.Sampled {
+			flags = 1
+		}
+		req.Header.Set("traceparent", fmt.Sprintf("00-%x-%x-%02x", sc.TraceID, sc.SpanID, flags))
+		if sc.TraceState != "" {
+			req.Header.Set("tracestate", sc.TraceState)
+		}
+	}
+	return req, span
+}
+
+// endSpan records the outcome of an attempt and ends its span.
+func endSpan(span Span, resp Response, errs []error) {
+	if len(errs) != 0 {
+		span.SetAttributes(Attribute{"error.type", fmt.Sprintf("%T", errors.Cause(errs[0]))})
+		span.RecordError(errs[0])
+	} else if resp != nil {
+		span.SetAttributes(Attribute{"http.response.status_code", resp.StatusCode})
+		if resp.StatusCode >= 400 {
+			span.SetAttributes(Attribute{"error.type", strconv.Itoa(resp.StatusCode)})
+			span.RecordError(errors.Errorf("HTTP status %s", resp.Status))
+		}
+	}
+	span.End()
+}
+
+// urlTemplate returns the path template of the request, or "" if no path parameter is set.
+func (s *SuperAgent) urlTemplate() string {
+	if s.pathParams == nil {
+		return ""
+	}
+	template := resolveURL(s.baseURL, s.Url)
+	if i := strings.Index(template, "://"); i >= 0 {
+		template = template[i+3:]
+		if j := strings.IndexByte(template, '/'); j >= 0 {
+			template = template[j:]
+		} else {
+			template = "/"
+		}
+	}
+	// drop the query, and query expressions like {?page}
+	if i := strings.IndexAny(template, "?#"); i >= 0 {
+		template = template[:i]
+		template = strings.TrimSuffix(template, "{")
+	}
+	return template
+}
+
+// serverPort returns the port req is sent to.
+func serverPort(req *http.Request) int {
+	if port, err := strconv.Atoi(req.URL.Port()); err == nil {
+		return port
+	}
+	switch req.URL.Scheme {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
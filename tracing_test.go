@@ -0,0 +1,140 @@
+package gorequest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// A memoryTracer exports the ended spans to memory.
+type memoryTracer struct {
+	mu     sync.Mutex
+	nextID byte
+	ended  []*memorySpan
+}
+
+type memorySpan struct {
+	tracer *memoryTracer
+	name   string
+	parent SpanContext
+	sc     SpanContext
+	attrs  map[string]interface{}
+	err    error
+}
+
+type memorySpanKey struct{}
+
+func (t *memoryTracer) Start(ctx context.Context, spanName string, attrs ...Attribute) (context.Context, Span) {
+	t.mu.Lock()
+	t.nextID++
+	span := &memorySpan{tracer: t, name: spanName, attrs: make(map[string]interface{})}
+	span.sc.SpanID[7] = t.nextID
+	t.mu.Unlock()
+
+	span.sc.TraceID[15] = 1
+	span.sc.Sampled = true
+	if parent, ok := ctx.Value(memorySpanKey{}).(*memorySpan); ok {
+		span.parent = parent.sc
+		span.sc.TraceID = parent.sc.TraceID
+		span.sc.TraceState = parent.sc.TraceState
+	}
+	span.SetAttributes(attrs...)
+	return context.WithValue(ctx, memorySpanKey{}, span), span
+}
+
+func (t *memoryTracer) spans() []*memorySpan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ended
+}
+
+func (s *memorySpan) SetAttributes(attrs ...Attribute) {
+	for _, attr := range attrs {
+		s.attrs[attr.Key] = attr.Value
+	}
+}
+
+func (s *memorySpan) RecordError(err error) { s.err = err }
+
+func (s *memorySpan) SpanContext() SpanContext { return s.sc }
+
+func (s *memorySpan) End() {
+	s.tracer.mu.Lock()
+	s.tracer.ended = append(s.tracer.ended, s)
+	s.tracer.mu.Unlock()
+}
+
+func TestTracer(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		traceparent []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		traceparent = append(traceparent, r.Header.Get("traceparent"))
+		if len(traceparent) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		if r.Header.Get("tracestate") != "vendor=1" {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer ts.Close()
+
+	tracer := &memoryTracer{}
+	parentCtx, parent := tracer.Start(context.Background(), "parent")
+	parent.(*memorySpan).sc.TraceState = "vendor=1"
+
+	resp, _, errs := NewClient(ts.URL).
+		SetTracer(tracer).
+		Get("/users/{id}{?page}").
+		Context(parentCtx).
+		PathParam("id", 42).
+		PathParam("page", 2).
+		Retry(1, 0, http.StatusServiceUnavailable).
+		End()
+	if errs != nil || resp.StatusCode != http.StatusOK {
+		t.Fatal(fmt.Sprintf("Expected request to succeed on retry | but got %v, %v", resp, errs))
+	}
+
+	spans := tracer.spans()
+	if len(spans) != 2 {
+		t.Fatal(fmt.Sprintf("Expected a span per attempt | but got %d", len(spans)))
+	}
+	for i, span := range spans {
+		if span.name != "GET /users/{id}" || span.attrs["url.template"] != "/users/{id}" ||
+			span.attrs["http.request.method"] != "GET" || span.attrs["url.full"] != ts.URL+"/users/42?page=2" ||
+			span.attrs["server.address"] != "127.0.0.1" {
+			t.Error(fmt.Sprintf("Expected HTTP client attributes | but got %q %v", span.name, span.attrs))
+		}
+		if span.parent != parent.SpanContext() || span.sc.TraceID != parent.SpanContext().TraceID {
+			t.Error("Expected spans to be children of the span in the context")
+		}
+		expected := fmt.Sprintf("00-%032x-%016x-01", span.sc.TraceID, span.sc.SpanID)
+		if traceparent[i] != expected {
+			t.Error(fmt.Sprintf("Expected traceparent %s | but got %s", expected, traceparent[i]))
+		}
+	}
+	if spans[0].attrs["http.response.status_code"] != 503 || spans[0].attrs["error.type"] != "503" || spans[0].err == nil {
+		t.Error(fmt.Sprintf("Expected first attempt to fail | but got %v, %v", spans[0].attrs, spans[0].err))
+	}
+	if _, ok := spans[0].attrs["http.request.resend_count"]; ok {
+		t.Error("Expected no resend count on the first attempt")
+	}
+	if spans[1].attrs["http.response.status_code"] != 200 || spans[1].attrs["http.request.resend_count"] != 1 || spans[1].err != nil {
+		t.Error(fmt.Sprintf("Expected retry to succeed | but got %v, %v", spans[1].attrs, spans[1].err))
+	}
+
+	// transport errors end the span too
+	tracer = &memoryTracer{}
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+	New().SetTracer(tracer).Get(closed.URL).End()
+	if spans := tracer.spans(); len(spans) != 1 || spans[0].err == nil || spans[0].name != "GET" || spans[0].attrs["error.type"] == nil {
+		t.Error(fmt.Sprintf("Expected failed span | but got %+v", spans))
+	}
+}
@@ -0,0 +1,394 @@
+package gorequest
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"weak"
+)
+
+// A PoolConfig tunes the connection pool of the transport, see ConnectionPool.
+// Zero values keep the defaults of net/http.
+type PoolConfig struct {
+	// MaxIdleConns limits the idle connections kept across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits the idle connections kept per host. net/http keeps 2.
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits the connections per host, including the ones in use.
+	// Requests wait for a free connection once it is reached.
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections which stayed idle that long.
+	IdleConnTimeout time.Duration
+	// HTTP2 tries HTTP/2 even if a TLSClientConfig, Proxy or dialer is set.
+	HTTP2 bool
+}
+
+// DefaultPoolConfig is a reasonable PoolConfig for services talking to a few hosts.
+var DefaultPoolConfig = PoolConfig{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 10,
+	IdleConnTimeout:     90 * time.Second,
+	HTTP2:               true,
+}
+
+// ConnectionPool enables keep-alives, which New disables, and tunes the connection pool.
+// Connections are then reused instead of doing a TCP and TLS handshake for every request:
+//
+//	base := gorequest.New().ConnectionPool(gorequest.DefaultPoolConfig)
+//	resp, body, errs := base.Clone().Get("https://example.com").End()
+//
+// Clones share the transport, and so the pool, of their parent. Set the pool on the base
+// request before cloning it, as calling ConnectionPool, TLSClientConfig or Proxy on a
+// clone gives it a transport of its own. See also Client.ConnectionPool.
+func (s *SuperAgent) ConnectionPool(config PoolConfig) *SuperAgent {
+	s.safeModifyTransport()
+	s.Transport.DisableKeepAlives = false
+	s.Transport.MaxIdleConns = config.MaxIdleConns
+	s.Transport.MaxIdleConnsPerHost = config.MaxIdleConnsPerHost
+	s.Transport.MaxConnsPerHost = config.MaxConnsPerHost
+	s.Transport.IdleConnTimeout = config.IdleConnTimeout
+	s.Transport.ForceAttemptHTTP2 = config.HTTP2
+	return s
+}
+
+// ConnectionPool returns a Client with keep-alives enabled and a tuned connection pool, see SuperAgent.ConnectionPool.
+// The requests of the returned Client, and of the Clients derived from it, share the pool.
+func (c *Client) ConnectionPool(config PoolConfig) *Client {
+	return c.with(func(s *SuperAgent) { s.ConnectionPool(config) })
+}
+
+// SetRoundTripper makes the SuperAgent send its requests through rt, for instance a mock
+// of the gorequesttest package or an *http.Transport of its own, instead of its Transport.
+// The http.Client shared with the parent of a clone is left unchanged.
+// Settings of the Transport, like TLSClientConfig or ConnectionPool, no longer apply.
+// A nil rt goes back to the Transport.
+func (s *SuperAgent) SetRoundTripper(rt http.RoundTripper) *SuperAgent {
+	s.safeModifyHttpClient()
+	s.ownRoundTripper = rt != nil
+	if rt == nil {
+		rt = s.Transport
+	}
+	s.Client.Transport = rt
+	return s
+}
+
+// SetRoundTripper returns a Client sending its requests through rt, see SuperAgent.SetRoundTripper.
+func (c *Client) SetRoundTripper(rt http.RoundTripper) *Client {
+	return c.with(func(s *SuperAgent) { s.SetRoundTripper(rt) })
+}
+
+// customRoundTripper tells whether the http.Client has a RoundTripper set by
+// SetRoundTripper, which the Transport doesn't replace.
+func (s *SuperAgent) customRoundTripper() bool {
+	return s.ownRoundTripper
+}
+
+// SetSharedTransport makes the SuperAgent send its requests through a process-wide
+// transport shared by every SuperAgent with the same transport settings: TLS config,
+// proxy, dialer, timeouts and pool settings. This way short-lived SuperAgents reuse
+// each other's connections instead of each opening, and leaking, its own:
+//
+//	gorequest.New().
+//	  SetSharedTransport(true).
+//	  ConnectionPool(gorequest.DefaultPoolConfig).
+//	  Get("https://example.com").
+//	  End()
+//
+// A transport with settings which can't be compared, such as a custom Proxy or
+// DialContext func set directly on the Transport, or a TLS config with callbacks like
+// VerifyPeerCertificate, is not shared. Root CAs and client certificates are compared
+// by content, so pools and certificates loaded separately from the same files match.
+// Modifying s.Transport afterwards doesn't change the shared transport, it makes the
+// next request use the shared transport matching the new settings.
+// A shared transport is dropped, and its idle connections closed, once no SuperAgent
+// using it is left. See SharedTransportStats for metrics on the shared transports.
+func (s *SuperAgent) SetSharedTransport(enable bool) *SuperAgent {
+	s.SharedTransport = enable
+	return s
+}
+
+// SetSharedTransport returns a Client using shared transports, see SuperAgent.SetSharedTransport.
+func (c *Client) SetSharedTransport(enable bool) *Client {
+	return c.with(func(s *SuperAgent) { s.SetSharedTransport(enable) })
+}
+
+// sharedTransport returns the shared transport to send the request with, or nil. The
+// SuperAgent keeps it, which keeps it registered.
+func (s *SuperAgent) sharedTransport() http.RoundTripper {
+	if !s.SharedTransport || s.Transport == nil {
+		return nil
+	}
+	key, ok := newTransportKey(s.Transport, s.proxyURL, s.dialTimeout)
+	if !ok {
+		return nil
+	}
+	rootCAs := transportRootCAs(s.Transport)
+	if s.shared == nil || !s.shared.matches(key, rootCAs) {
+		s.shared = sharedTransports.get(key, s.Transport)
+	}
+	return s.shared
+}
+
+// A transportKey holds the effective settings of a transport, but its root CAs which are
+// compared with CertPool.Equal.
+type transportKey struct {
+	proxy                  string
+	dialTimeout            time.Duration
+	tls                    string
+	disableKeepAlives      bool
+	disableCompression     bool
+	forceAttemptHTTP2      bool
+	maxIdleConns           int
+	maxIdleConnsPerHost    int
+	maxConnsPerHost        int
+	idleConnTimeout        time.Duration
+	tlsHandshakeTimeout    time.Duration
+	responseHeaderTimeout  time.Duration
+	expectContinueTimeout  time.Duration
+	maxResponseHeaderBytes int64
+	writeBufferSize        int
+	readBufferSize         int
+}
+
+// newTransportKey returns the key of t, or false if t has settings which can't be compared.
+// proxyURL and dialTimeout are the settings of Proxy and DialTimeout, as the funcs they
+// set on t can't be compared.
+func newTransportKey(t *http.Transport, proxyURL string, dialTimeout time.Duration) (transportKey, bool) {
+	if t.Proxy != nil && proxyURL == "" {
+		return transportKey{}, false
+	}
+	if t.DialContext != nil && dialTimeout == 0 {
+		return transportKey{}, false
+	}
+	if t.Dial != nil || t.DialTLS != nil || t.DialTLSContext != nil ||
+		t.TLSNextProto != nil || len(t.ProxyConnectHeader) != 0 || t.GetProxyConnectHeader != nil ||
+		t.OnProxyConnectResponse != nil {
+		return transportKey{}, false
+	}
+	tlsKey, ok := tlsConfigKey(t.TLSClientConfig)
+	if !ok {
+		return transportKey{}, false
+	}
+	return transportKey{
+		proxy:                  proxyURL,
+		dialTimeout:            dialTimeout,
+		tls:                    tlsKey,
+		disableKeepAlives:      t.DisableKeepAlives,
+		disableCompression:     t.DisableCompression,
+		forceAttemptHTTP2:      t.ForceAttemptHTTP2,
+		maxIdleConns:           t.MaxIdleConns,
+		maxIdleConnsPerHost:    t.MaxIdleConnsPerHost,
+		maxConnsPerHost:        t.MaxConnsPerHost,
+		idleConnTimeout:        t.IdleConnTimeout,
+		tlsHandshakeTimeout:    t.TLSHandshakeTimeout,
+		responseHeaderTimeout:  t.ResponseHeaderTimeout,
+		expectContinueTimeout:  t.ExpectContinueTimeout,
+		maxResponseHeaderBytes: t.MaxResponseHeaderBytes,
+		writeBufferSize:        t.WriteBufferSize,
+		readBufferSize:         t.ReadBufferSize,
+	}, true
+}
+
+// tlsConfigKey fingerprints the fields of a tls.Config commonly set by clients, so that
+// equal configs created separately share a transport. It returns false for configs with
+// callbacks, or with private keys which can't be marshaled, as they can't be compared.
+func tlsConfigKey(config *tls.Config) (string, bool) {
+	if config == nil {
+		return "", true
+	}
+	if config.GetClientCertificate != nil || config.VerifyPeerCertificate != nil || config.VerifyConnection != nil ||
+		config.GetConfigForClient != nil || config.GetCertificate != nil || config.Rand != nil || config.Time != nil ||
+		config.ClientSessionCache != nil || config.KeyLogWriter != nil || config.EncryptedClientHelloConfigList != nil {
+		return "", false
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%t %q %d %d %q %v %v %d\n", config.InsecureSkipVerify, config.ServerName,
+		config.MinVersion, config.MaxVersion, config.NextProtos, config.CipherSuites,
+		config.CurvePreferences, config.Renegotiation)
+	for _, certificate := range config.Certificates {
+		for _, der := range certificate.Certificate {
+			h.Write(der)
+		}
+		if certificate.PrivateKey != nil {
+			der, err := x509.MarshalPKCS8PrivateKey(certificate.PrivateKey)
+			if err != nil {
+				return "", false
+			}
+			h.Write(der)
+		}
+		h.Write([]byte("\n"))
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), true
+}
+
+// transportRootCAs returns the root CAs of t, nil for the system ones.
+func transportRootCAs(t *http.Transport) *x509.CertPool {
+	if t.TLSClientConfig == nil {
+		return nil
+	}
+	return t.TLSClientConfig.RootCAs
+}
+
+// TransportStats describes the shared transports, see SharedTransportStats.
+type TransportStats struct {
+	// Transports is the number of shared transports, one per distinct settings.
+	Transports int
+	// OpenConns is the number of connections currently open, idle or in use.
+	OpenConns int64
+	// Dials is the number of connections opened so far.
+	Dials int64
+}
+
+// SharedTransportStats returns metrics on the transports shared by SetSharedTransport.
+func SharedTransportStats() TransportStats {
+	return sharedTransports.stats()
+}
+
+// CloseIdleSharedTransports closes the idle connections of every shared transport.
+func CloseIdleSharedTransports() {
+	sharedTransports.closeIdleConnections()
+}
+
+// A sharedTransport is a transport of the registry. The SuperAgents using it hold it,
+// and the registry only weakly, so that it is dropped once unused.
+type sharedTransport struct {
+	transport *http.Transport
+	key       transportKey
+	// rootCAs is a copy of the root CAs of the transport, so that modifying the pool
+	// configured doesn't change the shared transport.
+	rootCAs *x509.CertPool
+}
+
+func (t *sharedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.transport.RoundTrip(req)
+}
+
+func (t *sharedTransport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
+
+func (t *sharedTransport) matches(key transportKey, rootCAs *x509.CertPool) bool {
+	return t.key == key && t.rootCAs.Equal(rootCAs)
+}
+
+// A transportRegistry holds one transport per distinct settings.
+type transportRegistry struct {
+	// first for 64-bit alignment of atomic operations
+	openConns  int64
+	dials      int64
+	mu         sync.Mutex
+	transports map[transportKey][]weak.Pointer[sharedTransport]
+}
+
+var sharedTransports = &transportRegistry{transports: make(map[transportKey][]weak.Pointer[sharedTransport])}
+
+// get returns the transport registered for key and the root CAs of t, registering a copy
+// of t if there is none.
+func (r *transportRegistry) get(key transportKey, t *http.Transport) *sharedTransport {
+	rootCAs := transportRootCAs(t)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range r.transports[key] {
+		if shared := p.Value(); shared != nil && shared.matches(key, rootCAs) {
+			return shared
+		}
+	}
+	transport := t.Clone()
+	if rootCAs != nil {
+		rootCAs = rootCAs.Clone()
+		transport.TLSClientConfig.RootCAs = rootCAs
+	}
+	// same dialer as http.DefaultTransport or DialTimeout, counting the connections
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	if key.dialTimeout != 0 {
+		dialer.Timeout = key.dialTimeout
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		atomic.AddInt64(&r.dials, 1)
+		atomic.AddInt64(&r.openConns, 1)
+		return &countedConn{Conn: conn, openConns: &r.openConns}, nil
+	}
+	shared := &sharedTransport{transport: transport, key: key, rootCAs: rootCAs}
+	r.transports[key] = append(r.transports[key], weak.Make(shared))
+	// the idle connections reference the http.Transport, not shared
+	runtime.AddCleanup(shared, r.evict, transport)
+	return shared
+}
+
+// evict closes the idle connections of a transport no longer used, and unregisters it.
+func (r *transportRegistry) evict(transport *http.Transport) {
+	transport.CloseIdleConnections()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune()
+}
+
+// prune unregisters the transports no longer used.
+func (r *transportRegistry) prune() {
+	for key, pointers := range r.transports {
+		live := pointers[:0]
+		for _, p := range pointers {
+			if p.Value() != nil {
+				live = append(live, p)
+			}
+		}
+		if len(live) == 0 {
+			delete(r.transports, key)
+		} else {
+			r.transports[key] = live
+		}
+	}
+}
+
+func (r *transportRegistry) stats() TransportStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.prune()
+	transports := 0
+	for _, pointers := range r.transports {
+		transports += len(pointers)
+	}
+	return TransportStats{
+		Transports: transports,
+		OpenConns:  atomic.LoadInt64(&r.openConns),
+		Dials:      atomic.LoadInt64(&r.dials),
+	}
+}
+
+func (r *transportRegistry) closeIdleConnections() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, pointers := range r.transports {
+		for _, p := range pointers {
+			if shared := p.Value(); shared != nil {
+				shared.CloseIdleConnections()
+			}
+		}
+	}
+}
+
+// A countedConn decrements openConns once closed.
+type countedConn struct {
+	net.Conn
+	openConns *int64
+	closed    int32
+}
+
+func (c *countedConn) Close() error {
+	if atomic.CompareAndSwapInt32(&c.closed, 0, 1) {
+		atomic.AddInt64(c.openConns, -1)
+	}
+	return c.Conn.Close()
+}
@@ -0,0 +1,225 @@
+package gorequest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+	"weak"
+)
+
+// newConnCountingServer returns a server counting the connections it accepted.
+func newConnCountingServer() (*httptest.Server, *int32) {
+	var conns int32
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	ts.Config.ConnState = func(c net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	ts.Start()
+	return ts, &conns
+}
+
+func TestConnectionPool(t *testing.T) {
+	ts, conns := newConnCountingServer()
+	defer ts.Close()
+
+	request := New()
+	for i := 0; i < 3; i++ {
+		request.Get(ts.URL).End()
+	}
+	if n := atomic.LoadInt32(conns); n != 3 {
+		t.Error(fmt.Sprintf("Expected 3 connections without pool | but got %d", n))
+	}
+
+	atomic.StoreInt32(conns, 0)
+	base := New().ConnectionPool(DefaultPoolConfig)
+	for i := 0; i < 3; i++ {
+		base.Clone().Get(ts.URL).End()
+	}
+	NewClient(ts.URL).ConnectionPool(DefaultPoolConfig).Get("/").End()
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Error(fmt.Sprintf("Expected 2 connections with pool | but got %d", n))
+	}
+	if base.Transport.DisableKeepAlives || base.Transport.MaxIdleConnsPerHost != 10 {
+		t.Error("Expected pool settings on transport")
+	}
+}
+
+func TestSharedTransport(t *testing.T) {
+	ts, conns := newConnCountingServer()
+	defer ts.Close()
+
+	before := SharedTransportStats()
+	// the agents hold their shared transport, which would be dropped without them
+	var agents []*SuperAgent
+	for i := 0; i < 3; i++ {
+		agent := New().SetSharedTransport(true).
+			ConnectionPool(PoolConfig{MaxIdleConnsPerHost: 3}).
+			TLSClientConfig(&tls.Config{InsecureSkipVerify: true})
+		agent.Get(ts.URL).End()
+		agents = append(agents, agent)
+	}
+	if n := atomic.LoadInt32(conns); n != 1 {
+		t.Error(fmt.Sprintf("Expected agents with equal settings to share 1 connection | but got %d", n))
+	}
+	if agents[0].shared == nil || agents[1].shared != agents[0].shared || agents[2].shared != agents[0].shared {
+		t.Error("Expected agents with equal settings to share 1 transport")
+	}
+	if stats := SharedTransportStats(); stats.Dials != before.Dials+1 || stats.Transports < 1 || stats.OpenConns < 1 {
+		t.Error(fmt.Sprintf("Expected 1 more dial | but got %+v, before %+v", stats, before))
+	}
+
+	// other settings get another transport
+	other := New().SetSharedTransport(true).ConnectionPool(PoolConfig{MaxIdleConnsPerHost: 3})
+	other.Get(ts.URL).End()
+	if n := atomic.LoadInt32(conns); n != 2 {
+		t.Error(fmt.Sprintf("Expected 2 connections | but got %d", n))
+	}
+	if other.shared == nil || other.shared == agents[0].shared {
+		t.Error("Expected agents with other settings to get another transport")
+	}
+
+	// custom dialers can't be compared
+	agent := New().SetSharedTransport(true)
+	agent.Transport.DialContext = (&net.Dialer{}).DialContext
+	if agent.sharedTransport() != nil {
+		t.Error("Expected a transport with a custom DialContext not to be shared")
+	}
+
+	CloseIdleSharedTransports()
+	runtime.KeepAlive(agents)
+	runtime.KeepAlive(other)
+}
+
+func TestSharedTransportTLS(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+
+	// configs with callbacks are never shared, whatever their address
+	verified := map[string]bool{}
+	for _, name := range []string{"a", "b"} {
+		name := name
+		agent := New().SetSharedTransport(true).TLSClientConfig(&tls.Config{
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func([][]byte, [][]*x509.Certificate) error {
+				verified[name] = true
+				return nil
+			},
+		})
+		if agent.sharedTransport() != nil {
+			t.Error("Expected a TLS config with callbacks not to be shared")
+		}
+		if _, _, errs := agent.Get(ts.URL).End(); errs != nil {
+			t.Fatal(errs)
+		}
+	}
+	if !verified["a"] || !verified["b"] {
+		t.Error(fmt.Sprintf("Expected each config to verify its own connections | but got %v", verified))
+	}
+
+	// root CAs are compared by content
+	newAgent := func(certificates ...*x509.Certificate) *SuperAgent {
+		pool := x509.NewCertPool()
+		for _, certificate := range certificates {
+			pool.AddCert(certificate)
+		}
+		return New().SetSharedTransport(true).TLSClientConfig(&tls.Config{RootCAs: pool})
+	}
+	a, b, c := newAgent(ts.Certificate()), newAgent(ts.Certificate()), newAgent()
+	if _, _, errs := a.Get(ts.URL).End(); errs != nil {
+		t.Fatal(errs)
+	}
+	if a.sharedTransport() != b.sharedTransport() || a.sharedTransport() == c.sharedTransport() {
+		t.Error("Expected agents to share a transport if and only if their root CAs are equal")
+	}
+	if _, _, errs := c.Get(ts.URL).End(); errs == nil {
+		t.Error("Expected a certificate error with other root CAs")
+	}
+
+	// and client certificates by their private key too
+	key1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	key2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	configKey := func(key *ecdsa.PrivateKey) string {
+		k, ok := tlsConfigKey(&tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{{1, 2, 3}}, PrivateKey: key}}})
+		if !ok {
+			t.Error("Expected a client certificate to be shareable")
+		}
+		return k
+	}
+	keyCopy := *key1
+	if configKey(key1) != configKey(&keyCopy) || configKey(key1) == configKey(key2) {
+		t.Error("Expected client certificates to be compared by content")
+	}
+}
+
+func TestSharedTransportEviction(t *testing.T) {
+	ts, _ := newConnCountingServer()
+	defer ts.Close()
+
+	agent := New().SetSharedTransport(true).ConnectionPool(PoolConfig{MaxIdleConnsPerHost: 7})
+	agent.Get(ts.URL).End()
+	key := agent.shared.key
+	shared := weak.Make(agent.shared)
+	agent = nil
+
+	for i := 0; i < 100 && shared.Value() != nil; i++ {
+		runtime.GC()
+		time.Sleep(10 * time.Millisecond)
+	}
+	if shared.Value() != nil {
+		t.Fatal("Expected the shared transport to be collected once unused")
+	}
+	SharedTransportStats()
+	sharedTransports.mu.Lock()
+	n := len(sharedTransports.transports[key])
+	sharedTransports.mu.Unlock()
+	if n != 0 {
+		t.Error(fmt.Sprintf("Expected the shared transport to be unregistered | but got %d", n))
+	}
+}
+
+// A staticRoundTripper answers every request with the same status.
+type staticRoundTripper int
+
+func (rt staticRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: int(rt), Header: http.Header{}, Body: http.NoBody, Request: req}, nil
+}
+
+func TestSetRoundTripper(t *testing.T) {
+	base := New()
+	clone := base.Clone().SetRoundTripper(staticRoundTripper(http.StatusTeapot)).TLSClientConfig(&tls.Config{})
+	if base.Client.Transport != base.Transport {
+		t.Error("Expected SetRoundTripper on a clone not to change its parent")
+	}
+	// neither TLSClientConfig nor sending the request replaces the round tripper
+	resp, _, errs := clone.Get("http://example.invalid").End()
+	if errs != nil || resp.StatusCode != http.StatusTeapot {
+		t.Error(fmt.Sprintf("Expected response of the round tripper | but got %v, %v", resp, errs))
+	}
+
+	// an *http.Transport is a round tripper of its own too
+	dialed := false
+	transport := &http.Transport{DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed = true
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ts.Close()
+	if _, _, errs := New().SetRoundTripper(transport).Get(ts.URL).End(); errs != nil || !dialed {
+		t.Error(fmt.Sprintf("Expected the request sent through the *http.Transport | but got %v, dialed %t", errs, dialed))
+	}
+}